@@ -1,10 +1,20 @@
-module "bitbucket.org/fflo/semix"
+module bitbucket.org/fflo/semix
+
+go 1.27.1
+
+require (
+	bitbucket.org/fflo/sparsetable v1.0.4
+	github.com/BurntSushi/toml v0.3.0
+	github.com/fatih/color v1.6.0
+	github.com/mattn/go-isatty v0.0.24
+	github.com/pkg/errors v0.8.0
+	github.com/spf13/cobra v0.0.1
+	github.com/spf13/pflag v1.0.0
+	golang.org/x/net v0.0.0-20180218175443-cbe0f9307d01
+	golang.org/x/text v0.3.0
+)
 
 require (
-	"bitbucket.org/fflo/sparsetable" v1.0.4
-	"github.com/BurntSushi/toml" v0.3.0
-	"github.com/fatih/color" v1.6.0
-	"github.com/spf13/cobra" v0.0.1
-	"github.com/spf13/pflag" v1.0.0
-	"golang.org/x/net" v0.0.0-20180218175443-cbe0f9307d01
+	github.com/mattn/go-colorable v0.1.15 // indirect
+	golang.org/x/sys v0.29.0 // indirect
 )
@@ -28,6 +28,19 @@ func WithResolvers(rs ...rest.Resolver) Option {
 	}
 }
 
+// WithResolver appends a resolver with the given name,
+// memory size and parameters. The name must be the name of a
+// resolver that is registered in the daemon.
+func WithResolver(name string, memsize int, params map[string]string) Option {
+	return func(c *Client) {
+		c.rs = append(c.rs, rest.Resolver{
+			Name:       name,
+			MemorySize: memsize,
+			Params:     params,
+		})
+	}
+}
+
 // WithErrorLimits sets the errorlimits for the client to use.
 func WithErrorLimits(ks ...int) Option {
 	return func(c *Client) {
@@ -50,13 +63,57 @@ func WithMax(m int) Option {
 	}
 }
 
+// WithMinScore sets the minimal score of disambiguated entries.
+func WithMinScore(m float64) Option {
+	return func(c *Client) {
+		c.minScore = m
+	}
+}
+
+// WithNestedMatches enables or disables the
+// reporting of nested and overlapping matches.
+func WithNestedMatches(nested bool) Option {
+	return func(c *Client) {
+		c.nestedMatches = nested
+	}
+}
+
+// WithMatcher selects the exact matcher
+// (rest.DFAMatcher or rest.AhoCorasickMatcher).
+func WithMatcher(name string) Option {
+	return func(c *Client) {
+		c.matcher = name
+	}
+}
+
+// WithConfusions sets the name of the server's confusion
+// table that is used for approximate searches.
+func WithConfusions(name string) Option {
+	return func(c *Client) {
+		c.confusions = name
+	}
+}
+
+// WithNested selects the nested entries of queries
+// (rest.NestedInclude, rest.NestedExclude or rest.NestedOnly).
+func WithNested(mode string) Option {
+	return func(c *Client) {
+		c.nested = mode
+	}
+}
+
 // Client represents a connection to the rest service.
 type Client struct {
-	client    *http.Client
-	host      string
-	rs        []rest.Resolver
-	ks        []int
-	skip, max int
+	client        *http.Client
+	host          string
+	rs            []rest.Resolver
+	ks            []int
+	skip, max     int
+	minScore      float64
+	nested        string
+	nestedMatches bool
+	matcher       string
+	confusions    string
 }
 
 // New create a new client that connects to the rest at
@@ -139,9 +196,11 @@ func (c *Client) ConceptID(id int) (*semix.Concept, error) {
 // Get searches the index for the given query.
 func (c *Client) Get(q string) ([]index.Entry, error) {
 	data := struct {
-		Q    string
-		N, S int
-	}{q, c.max, c.skip}
+		Q      string
+		N, S   int
+		M      float64
+		Nested string
+	}{q, c.max, c.skip, c.minScore, c.nested}
 	query, err := rest.EncodeQuery(data)
 	if err != nil {
 		return nil, err
@@ -155,9 +214,12 @@ func (c *Client) Get(q string) ([]index.Entry, error) {
 // PutURL puts the given url into the index.
 func (c *Client) PutURL(url string) ([]index.Entry, error) {
 	return c.doPut(rest.PutData{
-		URL:       url,
-		Errors:    c.ks,
-		Resolvers: c.rs,
+		URL:        url,
+		Errors:     c.ks,
+		Resolvers:  c.rs,
+		Nested:     c.nestedMatches,
+		Matcher:    c.matcher,
+		Confusions: c.confusions,
 	})
 }
 
@@ -170,10 +232,13 @@ func (c *Client) PutLocalFile(path string) ([]index.Entry, error) {
 		return nil, err
 	}
 	return c.doPut(rest.PutData{
-		URL:       abs,
-		Local:     true,
-		Errors:    c.ks,
-		Resolvers: c.rs,
+		URL:        abs,
+		Local:      true,
+		Errors:     c.ks,
+		Resolvers:  c.rs,
+		Nested:     c.nestedMatches,
+		Matcher:    c.matcher,
+		Confusions: c.confusions,
 	})
 }
 
@@ -182,6 +247,9 @@ func (c *Client) PutString(content, ct string) ([]index.Entry, error) {
 	return c.doPut(rest.PutData{
 		Errors:      c.ks,
 		Resolvers:   c.rs,
+		Nested:      c.nestedMatches,
+		Matcher:     c.matcher,
+		Confusions:  c.confusions,
 		Content:     content,
 		ContentType: ct,
 	})
@@ -197,6 +265,9 @@ func (c *Client) PutContent(r io.Reader, url, ct string) ([]index.Entry, error)
 		URL:         url,
 		Errors:      c.ks,
 		Resolvers:   c.rs,
+		Nested:      c.nestedMatches,
+		Matcher:     c.matcher,
+		Confusions:  c.confusions,
 		Content:     string(content),
 		ContentType: ct,
 	})
@@ -213,14 +284,61 @@ func (c *Client) doPut(data rest.PutData) ([]index.Entry, error) {
 }
 
 // Ctx returns the context of a given citation.
+// The positions refer to the normalized document.
 func (c *Client) Ctx(u string, b, e, n int) (rest.Context, error) {
-	url := fmt.Sprintf("%s/ctx?url=%s&b=%d&e=%d&n=%d",
-		c.host, url.QueryEscape(u), b, e, n)
+	return c.ctx(u, b, e, n, false)
+}
+
+// OriginalCtx returns the context of a given citation.
+// The positions refer to the original document
+// (see index.Entry.OriginalBegin and index.Entry.OriginalEnd).
+func (c *Client) OriginalCtx(u string, b, e, n int) (rest.Context, error) {
+	return c.ctx(u, b, e, n, true)
+}
+
+func (c *Client) ctx(u string, b, e, n int, o bool) (rest.Context, error) {
+	url := fmt.Sprintf("%s/ctx?url=%s&b=%d&e=%d&n=%d&o=%t",
+		c.host, url.QueryEscape(u), b, e, n, o)
 	var ctx rest.Context
 	err := c.get(url, &ctx)
 	return ctx, err
 }
 
+// EvalRule evaluates the given rule expression using a memory
+// of size n that contains the given context concepts.
+func (c *Client) EvalRule(rule string, n int, concepts ...string) (rest.RuleTrace, error) {
+	return c.evalRule(rule, "", n, concepts)
+}
+
+// EvalRuleURL evaluates the rule of the concept with the given URL using
+// a memory of size n that contains the given context concepts.
+func (c *Client) EvalRuleURL(u string, n int, concepts ...string) (rest.RuleTrace, error) {
+	return c.evalRule("", u, n, concepts)
+}
+
+func (c *Client) evalRule(rule, u string, n int, concepts []string) (rest.RuleTrace, error) {
+	data := struct {
+		Rule, URL string
+		Concepts  []string
+		N         int
+	}{rule, u, concepts, n}
+	var trace rest.RuleTrace
+	query, err := rest.EncodeQuery(data)
+	if err != nil {
+		return trace, err
+	}
+	err = c.get(c.host+"/rule/eval"+query, &trace)
+	return trace, err
+}
+
+// ReloadRules reloads the rules of the daemon.
+func (c *Client) ReloadRules() (rest.RulesReload, error) {
+	url := fmt.Sprintf("%s/rules/reload", c.host)
+	var res rest.RulesReload
+	err := c.get(url, &res)
+	return res, err
+}
+
 // Flush flushes the index.
 func (c *Client) Flush() error {
 	url := fmt.Sprintf("%s/flush", c.host)
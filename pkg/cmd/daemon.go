@@ -6,11 +6,14 @@ import (
 	"os/signal"
 	"path/filepath"
 	"syscall"
+	"time"
 
 	"bitbucket.org/fflo/semix/pkg/index"
+	"bitbucket.org/fflo/semix/pkg/resolve"
 	"bitbucket.org/fflo/semix/pkg/resource"
 	"bitbucket.org/fflo/semix/pkg/rest"
 	"bitbucket.org/fflo/semix/pkg/say"
+	"bitbucket.org/fflo/semix/pkg/semix"
 	"github.com/spf13/cobra"
 )
 
@@ -24,9 +27,13 @@ var daemonCmd = &cobra.Command{
 }
 
 var (
-	daemonDir       string
-	daemonNoCache   bool
-	indexBufferSize int
+	daemonDir        string
+	daemonNoCache    bool
+	indexBufferSize  int
+	daemonRules      []string
+	daemonReload     time.Duration
+	daemonLenient    bool
+	daemonConfusions string
 )
 
 func semixDir() string {
@@ -44,6 +51,14 @@ func init() {
 		false, "do not load cached resources")
 	daemonCmd.Flags().IntVar(&indexBufferSize, "index-size",
 		index.DefaultBufferSize, "set buffer size of index")
+	daemonCmd.Flags().StringSliceVar(&daemonRules, "rules",
+		nil, "load additional rule files")
+	daemonCmd.Flags().DurationVar(&daemonReload, "rules-reload",
+		5*time.Second, "set interval to check rule files for changes (0 disables)")
+	daemonCmd.Flags().BoolVar(&daemonLenient, "lenient-rules",
+		false, "skip invalid rules instead of failing")
+	daemonCmd.Flags().StringVar(&daemonConfusions, "confusions-dir",
+		"", "load the named confusion tables (*"+resource.ConfusionTableExt+") of the given directory")
 }
 
 func daemon(cmd *cobra.Command, args []string) error {
@@ -73,9 +88,54 @@ func newServer(res string) (*rest.Server, error) {
 	if err != nil {
 		return nil, err
 	}
-	r, err := resource.Parse(res, !daemonNoCache)
+	c, err := resource.Read(res)
 	if err != nil {
 		return nil, err
 	}
-	return rest.New(daemonHost, daemonDir, r, index)
+	r, err := c.Parse(!daemonNoCache)
+	if err != nil {
+		return nil, err
+	}
+	model, err := readModel(c)
+	if err != nil {
+		return nil, err
+	}
+	costs, err := c.EditCosts()
+	if err != nil {
+		return nil, err
+	}
+	var tables map[string]semix.EditCosts
+	if daemonConfusions != "" {
+		if tables, err = resource.ReadConfusionTables(daemonConfusions); err != nil {
+			return nil, err
+		}
+	}
+	return rest.New(daemonHost, daemonDir, r, index,
+		rest.WithRuleFiles(c.File.Rules...),
+		rest.WithRuleFiles(daemonRules...),
+		rest.WithRuleReloadInterval(daemonReload),
+		rest.WithLenientRules(daemonLenient),
+		rest.WithBayesModel(model),
+		rest.WithEditCosts(costs),
+		rest.WithConfusionTables(tables),
+	)
+}
+
+// readModel reads the model of the bayes resolver.
+// If no model exists, nil is returned.
+func readModel(c *resource.Config) (*resolve.Model, error) {
+	path, err := c.ModelPath(rest.BayesResolver)
+	if err != nil {
+		return nil, nil
+	}
+	is, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = is.Close() }()
+	say.Info("loading model %s", path)
+	return resolve.ReadModel(is)
 }
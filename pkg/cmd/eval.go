@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sort"
+
+	"bitbucket.org/fflo/semix/pkg/eval"
+	"bitbucket.org/fflo/semix/pkg/gold"
+	"bitbucket.org/fflo/semix/pkg/resolve"
+	"bitbucket.org/fflo/semix/pkg/resource"
+	"bitbucket.org/fflo/semix/pkg/rest"
+	"bitbucket.org/fflo/semix/pkg/semix"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var evalCmd = &cobra.Command{
+	Use:   "eval <resource> <gold...>",
+	Short: "Evaluate the annotations against gold annotations",
+	Long: `The eval command annotates the documents of the given gold
+annotated files locally, using the same pipeline as the put command.
+It reports precision, recall and F1 for exact and overlapping spans,
+split by direct and fuzzy matches and by ambiguous and unambiguous
+concepts.`,
+	RunE:         evaluate,
+	Args:         cobra.MinimumNArgs(2),
+	SilenceUsage: true,
+}
+
+func init() {
+	evalCmd.Flags().BoolVar(&rulesNoCache, "no-cache",
+		false, "do not load cached resources")
+	evalCmd.Flags().StringSliceVar(&rulesFiles, "rules",
+		nil, "load additional rule files")
+	evalCmd.Flags().StringVar(&confusions, "confusions", "",
+		"use the edit costs of the given confusion table for approximate searches")
+	addResolverFlags(evalCmd.Flags())
+}
+
+func evaluate(cmd *cobra.Command, args []string) error {
+	setupSay()
+	rs, err := makeResolvers()
+	if err != nil {
+		return errors.Wrapf(err, "eval")
+	}
+	sort.Ints(levs)
+	c, err := resource.Read(args[0])
+	if err != nil {
+		return err
+	}
+	model, err := readModel(c)
+	if err != nil {
+		return err
+	}
+	costs, err := c.EditCosts()
+	if err != nil {
+		return err
+	}
+	if confusions != "" {
+		if costs, err = resource.ReadEditCosts(confusions); err != nil {
+			return err
+		}
+	}
+	r, rules, err := loadRules(args[0])
+	if err != nil {
+		return err
+	}
+	costs = costs.Normalize(r.Normalizer)
+	if err := costs.Check(); err != nil {
+		return err
+	}
+	p := rest.PutData{
+		Errors:    levs,
+		Resolvers: rs,
+		Nested:    nested,
+		Matcher:   matcher,
+	}
+	resources := resolve.Resources{Graph: r.Graph, Rules: rules, Model: model, Cache: resolve.NewCache()}
+	res := eval.NewResult()
+	for _, file := range args[1:] {
+		fres, err := evalFile(p, r, costs, resources, file)
+		if err != nil {
+			return errors.Wrapf(err, "cannot evaluate %s", file)
+		}
+		res.Add(fres)
+	}
+	if jsonOutput {
+		return json.NewEncoder(os.Stdout).Encode(res)
+	}
+	return eval.WriteText(os.Stdout, res)
+}
+
+func evalFile(
+	p rest.PutData,
+	r *semix.Resource,
+	costs semix.EditCosts,
+	resources resolve.Resources,
+	file string,
+) (eval.Result, error) {
+	doc, err := gold.Read(file)
+	if err != nil {
+		return eval.Result{}, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	rec := eval.NewRecorder()
+	s, err := p.MatchStream(ctx, r.DFA, r.AhoCorasick, costs,
+		r.Normalizer.Normalize(ctx, semix.Read(ctx, semix.NewFileDocument(doc.Path))))
+	if err != nil {
+		return eval.Result{}, err
+	}
+	s, err = p.ResolveStream(ctx, resources, rec.Record(ctx, s))
+	if err != nil {
+		return eval.Result{}, err
+	}
+	ms, err := rec.Collect(s)
+	if err != nil {
+		return eval.Result{}, err
+	}
+	return eval.Evaluate(doc.Annotations, ms), nil
+}
@@ -8,6 +8,7 @@ import (
 
 	"bitbucket.org/fflo/semix/pkg/client"
 	"bitbucket.org/fflo/semix/pkg/index"
+	"bitbucket.org/fflo/semix/pkg/rest"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
@@ -22,18 +23,26 @@ var getCmd = &cobra.Command{
 }
 
 var (
-	getMax  int
-	getSkip int
+	getMax      int
+	getSkip     int
+	getMinScore float64
+	getNested   string
 )
 
 func init() {
 	getCmd.Flags().IntVarP(&getMax, "max", "m", 0, "set max number of entries")
 	getCmd.Flags().IntVarP(&getSkip, "skip", "s", 0, "set number of entries to skip")
+	getCmd.Flags().Float64Var(&getMinScore, "min-score", 0,
+		"skip disambiguated entries with a score less than the given score")
+	getCmd.Flags().StringVar(&getNested, "nested", rest.NestedInclude,
+		"select nested entries; allowed values are include,exclude,only")
 }
 
 func get(cmd *cobra.Command, args []string) error {
 	setupSay()
-	client := client.New(DaemonHost(), client.WithSkip(getSkip), client.WithMax(getMax))
+	client := client.New(DaemonHost(), client.WithSkip(getSkip),
+		client.WithMax(getMax), client.WithMinScore(getMinScore),
+		client.WithNested(getNested))
 	for _, query := range args {
 		if err := doGet(client, query); err != nil {
 			return err
@@ -5,22 +5,37 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 
 	"bitbucket.org/fflo/semix/pkg/client"
 	"bitbucket.org/fflo/semix/pkg/index"
+	"bitbucket.org/fflo/semix/pkg/resolve"
 	"bitbucket.org/fflo/semix/pkg/rest"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 var (
-	putLocal  bool
-	resolvers []string
-	levs      []int
-	memsize   int
-	threshold float64
-	putCmd    = &cobra.Command{
+	putLocal   bool
+	resolvers  []string
+	levs       []int
+	memsize    int
+	threshold  float64
+	confidence float64
+	vote       string
+	voteWindow int
+	iterations int
+	predicates []string
+	decay      string
+	decayRate  float64
+	decayOffs  bool
+	lookahead  int
+	nested     bool
+	matcher    string
+	confusions string
+	putCmd     = &cobra.Command{
 		Use:   "put [paths...]",
 		Short: "Put a file into the semantic index",
 		Long: `The put command puts files into the semantic index.
@@ -46,19 +61,51 @@ are not stored locally`,
 func init() {
 	putCmd.Flags().BoolVarP(&putLocal, "local", "l", false,
 		"do not upload files; use local files")
-	putCmd.Flags().StringSliceVarP(&resolvers, "resolver", "r", []string{},
-		"use resolvers in given order; allowed values are thematic,ruled,simple")
-	putCmd.Flags().IntSliceVarP(&levs, "ks", "k", []int{},
+	putCmd.Flags().StringVar(&confusions, "confusions", "",
+		"use the edit costs of the daemon's named confusion table for approximate searches")
+	addResolverFlags(putCmd.Flags())
+}
+
+// addResolverFlags adds the flags to configure the
+// matching and the resolvers to the given flag set.
+func addResolverFlags(flags *pflag.FlagSet) {
+	flags.StringSliceVarP(&resolvers, "resolver", "r", []string{},
+		"use resolvers in given order; allowed values are "+strings.Join(resolve.Names(), ",")+
+			" with optional parameters name(key=value ...),"+
+			"ensemble(name[:weight] ...) and document(name)")
+	flags.IntSliceVarP(&levs, "ks", "k", []int{},
 		"add approximate searches with the given error limits")
-	putCmd.Flags().IntVarP(&memsize, "memory-size", "m", 10,
+	flags.BoolVar(&nested, "nested", false,
+		"report nested and overlapping matches")
+	flags.StringVar(&matcher, "matcher", rest.DFAMatcher,
+		"set the exact matcher; allowed values are dfa,ahocorasick")
+	flags.IntVarP(&memsize, "memory-size", "m", 10,
 		"set the memory size used by the resolvers")
-	putCmd.Flags().Float64VarP(&threshold, "threshold", "t", 0.5,
+	flags.Float64VarP(&threshold, "threshold", "t", 0.5,
 		"set the threshold for the thematic resolver")
+	flags.Float64Var(&confidence, "confidence", 0,
+		"set the minimal confidence for ensemble resolvers")
+	flags.StringVar(&vote, "vote", "majority",
+		"set the vote of document resolvers; allowed values are majority,score")
+	flags.IntVar(&voteWindow, "vote-window", 0,
+		"set the maximal number of tokens of a document resolved together by document resolvers (default whole documents)")
+	flags.IntVar(&iterations, "iterations", resolve.DefaultIterations,
+		"set the number of iterations of the pagerank resolver")
+	flags.StringSliceVar(&predicates, "predicates", []string{},
+		"set the predicates followed by the pagerank resolver (default all)")
+	flags.StringVar(&decay, "decay", "none",
+		"set the decay of the resolvers' memory; allowed values are none,linear,exponential")
+	flags.Float64Var(&decayRate, "decay-rate", 0.1,
+		"set the decay rate of the resolvers' memory")
+	flags.BoolVar(&decayOffs, "decay-offsets", false,
+		"measure the decay distance in characters instead of tokens")
+	flags.IntVar(&lookahead, "lookahead", 0,
+		"set the number of following tokens used as right context by the resolvers")
 }
 
 func put(cmd *cobra.Command, args []string) error {
 	setupSay()
-	rs, err := rest.MakeResolvers(threshold, memsize, resolvers)
+	rs, err := makeResolvers()
 	if err != nil {
 		return errors.Wrapf(err, "put")
 	}
@@ -67,6 +114,9 @@ func put(cmd *cobra.Command, args []string) error {
 		DaemonHost(),
 		client.WithErrorLimits(levs...),
 		client.WithResolvers(rs...),
+		client.WithNestedMatches(nested),
+		client.WithMatcher(matcher),
+		client.WithConfusions(confusions),
 	)
 	for _, arg := range args {
 		if err := putPath(client, arg); err != nil {
@@ -76,6 +126,53 @@ func put(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// makeResolvers creates the resolvers from the resolver flags.
+func makeResolvers() ([]rest.Resolver, error) {
+	rs, err := rest.MakeResolvers(threshold, memsize, resolvers)
+	if err != nil {
+		return nil, err
+	}
+	for i := range rs {
+		configureResolver(&rs[i])
+	}
+	return rs, nil
+}
+
+func configureResolver(r *rest.Resolver) {
+	r.Decay = decay
+	r.DecayRate = decayRate
+	r.DecayOffsets = decayOffs
+	r.Lookahead = lookahead
+	switch r.Name {
+	case rest.EnsembleResolver:
+		r.Threshold = confidence
+	case rest.PageRankResolver:
+		setDefaultParam(r, "iterations", strconv.Itoa(iterations))
+		if len(predicates) > 0 {
+			setDefaultParam(r, "predicates", strings.Join(predicates, "|"))
+		}
+	}
+	if r.Vote != "" {
+		r.Vote = vote
+		r.VoteWindow = voteWindow
+	}
+	for i := range r.Members {
+		configureResolver(&r.Members[i])
+	}
+}
+
+// setDefaultParam sets the parameter if it was not
+// already given with the name of the resolver.
+func setDefaultParam(r *rest.Resolver, key, val string) {
+	if _, ok := r.Params[key]; ok {
+		return
+	}
+	if r.Params == nil {
+		r.Params = make(map[string]string)
+	}
+	r.Params[key] = val
+}
+
 func putPath(client *client.Client, path string) error {
 	if isURL(path) {
 		return putFileOrURL(client, path)
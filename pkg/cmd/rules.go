@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"bitbucket.org/fflo/semix/pkg/resource"
+	"bitbucket.org/fflo/semix/pkg/rule"
+	"bitbucket.org/fflo/semix/pkg/ruletest"
+	"bitbucket.org/fflo/semix/pkg/say"
+	"bitbucket.org/fflo/semix/pkg/searcher"
+	"bitbucket.org/fflo/semix/pkg/semix"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var rulesCmd = &cobra.Command{
+	Use:   "rules",
+	Short: "Work with disambiguation rules",
+	Long:  `The rules command groups commands to work with disambiguation rules.`,
+}
+
+var rulesTestCmd = &cobra.Command{
+	Use:   "test <resource> <file...>",
+	Short: "Test disambiguation rules",
+	Long: `The test command runs the test cases of the given test files
+against the compiled rules of the given resource and prints
+a pass/fail report.`,
+	RunE:         rulesTest,
+	Args:         cobra.MinimumNArgs(2),
+	SilenceUsage: true,
+}
+
+var rulesLintCmd = &cobra.Command{
+	Use:   "lint <resource>",
+	Short: "Lint disambiguation rules",
+	Long: `The lint command checks the rules of the given resource
+and reports unresolved or ambiguous concept references,
+type errors, constant rules and unreachable comparisons.`,
+	RunE:         rulesLint,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+}
+
+var (
+	rulesNoCache bool
+	rulesFiles   []string
+	rulesJUnit   string
+)
+
+func init() {
+	rulesCmd.PersistentFlags().BoolVar(&rulesNoCache, "no-cache",
+		false, "do not load cached resources")
+	rulesCmd.PersistentFlags().StringSliceVar(&rulesFiles, "rules",
+		nil, "load additional rule files")
+	rulesTestCmd.Flags().StringVar(&rulesJUnit, "junit",
+		"", "write JUnit XML report to file (- for stdout)")
+	rulesCmd.AddCommand(rulesTestCmd)
+	rulesCmd.AddCommand(rulesLintCmd)
+}
+
+func rulesLint(cmd *cobra.Command, args []string) error {
+	setupSay()
+	r, rs, err := readRules(args[0])
+	if err != nil {
+		return err
+	}
+	fs := rule.LintMap(rs, searcher.New(r.Graph, r.Dictionary, searcher.WithNormalizer(r.Normalizer)).LookupIDs)
+	var errs int
+	for _, f := range fs {
+		if f.Severity == rule.SeverityError {
+			errs++
+		}
+		if jsonOutput {
+			_ = json.NewEncoder(os.Stdout).Encode(f)
+		} else {
+			fmt.Println(f)
+		}
+	}
+	if errs > 0 {
+		return fmt.Errorf("%d invalid rules", errs)
+	}
+	return nil
+}
+
+func rulesTest(cmd *cobra.Command, args []string) error {
+	setupSay()
+	r, rules, err := loadRules(args[0])
+	if err != nil {
+		return err
+	}
+	runner := ruletest.Runner{
+		Rules:    rules,
+		Graph:    r.Graph,
+		Searcher: searcher.New(r.Graph, r.Dictionary, searcher.WithNormalizer(r.Normalizer)),
+	}
+	var reports []ruletest.Report
+	var failed int
+	for _, file := range args[1:] {
+		suite, err := ruletest.Read(file)
+		if err != nil {
+			return errors.Wrapf(err, "cannot read test file: %s", file)
+		}
+		report := runner.Run(suite)
+		failed += report.Failures() + report.Errors()
+		reports = append(reports, report)
+	}
+	if err := writeRulesReport(reports); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d test cases failed", failed)
+	}
+	return nil
+}
+
+func writeRulesReport(reports []ruletest.Report) error {
+	switch rulesJUnit {
+	case "":
+		return ruletest.WriteText(os.Stdout, reports...)
+	case "-":
+		return ruletest.WriteJUnit(os.Stdout, reports...)
+	default:
+		if err := ruletest.WriteText(os.Stdout, reports...); err != nil {
+			return err
+		}
+		file, err := os.Create(rulesJUnit)
+		if err != nil {
+			return errors.Wrapf(err, "cannot write JUnit report")
+		}
+		if err := ruletest.WriteJUnit(file, reports...); err != nil {
+			_ = file.Close()
+			return errors.Wrapf(err, "cannot write JUnit report")
+		}
+		return file.Close()
+	}
+}
+
+// loadRules loads the resource and compiles its rules together with
+// the rules of the configured and the additional rule files.
+// Rules that cannot be compiled are reported and skipped.
+func loadRules(res string) (*semix.Resource, rule.Map, error) {
+	r, rs, err := readRules(res)
+	if err != nil {
+		return nil, nil, err
+	}
+	s := searcher.New(r.Graph, r.Dictionary, searcher.WithNormalizer(r.Normalizer))
+	rules, errs := rule.Map(nil).Update(rs, s.LookupID)
+	for _, err := range errs {
+		say.Info("error: %s", err)
+	}
+	return r, rules, nil
+}
+
+// readRules loads the resource and returns its rules together with
+// the rules of the configured and the additional rule files.
+func readRules(res string) (*semix.Resource, map[string]string, error) {
+	c, err := resource.Read(res)
+	if err != nil {
+		return nil, nil, err
+	}
+	r, err := c.Parse(!rulesNoCache)
+	if err != nil {
+		return nil, nil, err
+	}
+	rs, err := resource.MergeRules(r.Rules, append(c.File.Rules, rulesFiles...)...)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "cannot read rule files")
+	}
+	return r, rs, nil
+}
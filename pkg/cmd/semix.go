@@ -68,6 +68,9 @@ func init() {
 	semixCmd.AddCommand(infoCmd)
 	semixCmd.AddCommand(daemonCmd)
 	semixCmd.AddCommand(httpdCmd)
+	semixCmd.AddCommand(rulesCmd)
+	semixCmd.AddCommand(trainCmd)
+	semixCmd.AddCommand(evalCmd)
 }
 
 func setupSay() {
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"context"
+	"os"
+
+	"bitbucket.org/fflo/semix/pkg/gold"
+	"bitbucket.org/fflo/semix/pkg/resolve"
+	"bitbucket.org/fflo/semix/pkg/resource"
+	"bitbucket.org/fflo/semix/pkg/rest"
+	"bitbucket.org/fflo/semix/pkg/say"
+	"bitbucket.org/fflo/semix/pkg/semix"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var trainCmd = &cobra.Command{
+	Use:   "train <resource> <gold...>",
+	Short: "Train the bayes resolver",
+	Long: `The train command trains the model of the bayes resolver
+with the given gold annotated documents. The model is written
+next to the cache of the resource.`,
+	RunE:         train,
+	Args:         cobra.MinimumNArgs(2),
+	SilenceUsage: true,
+}
+
+var (
+	trainNoCache bool
+	trainMemsize int
+	trainOutput  string
+)
+
+func init() {
+	trainCmd.Flags().BoolVar(&trainNoCache, "no-cache",
+		false, "do not load cached resources")
+	trainCmd.Flags().IntVarP(&trainMemsize, "memory-size", "m", 10,
+		"set the memory size used for training")
+	trainCmd.Flags().StringVarP(&trainOutput, "output", "o", "",
+		"write model to file instead of next to the cache")
+}
+
+func train(cmd *cobra.Command, args []string) error {
+	setupSay()
+	c, err := resource.Read(args[0])
+	if err != nil {
+		return err
+	}
+	r, err := c.Parse(!trainNoCache)
+	if err != nil {
+		return err
+	}
+	model := resolve.NewModel(trainMemsize)
+	for _, file := range args[1:] {
+		if err := trainFile(model, r, file); err != nil {
+			return errors.Wrapf(err, "cannot train %s", file)
+		}
+	}
+	path := trainOutput
+	if path == "" {
+		if path, err = c.ModelPath(rest.BayesResolver); err != nil {
+			return err
+		}
+	}
+	say.Info("writing model %s (%g samples, %d senses)",
+		path, model.N, len(model.Senses))
+	out, err := os.Create(path)
+	if err != nil {
+		return errors.Wrapf(err, "cannot write model")
+	}
+	if err := model.Write(out); err != nil {
+		_ = out.Close()
+		return errors.Wrapf(err, "cannot write model")
+	}
+	return out.Close()
+}
+
+func trainFile(model *resolve.Model, r *semix.Resource, file string) error {
+	doc, err := gold.Read(file)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s := semix.Match(ctx, semix.DFAMatcher{DFA: r.DFA},
+		r.Normalizer.Normalize(ctx, semix.Read(ctx, semix.NewFileDocument(doc.Path))))
+	return model.Train(ctx, s, func(t semix.Token) string {
+		a, _ := doc.Find(t.Original())
+		return a.URL
+	})
+}
@@ -0,0 +1,299 @@
+// Package eval evaluates the matching and disambiguation quality
+// against gold standard annotations (see bitbucket.org/fflo/semix/pkg/gold).
+// The matches of a document are compared with the gold annotations
+// using exact and overlapping spans. The results are split by
+// direct and fuzzy matches and by ambiguous and unambiguous concepts.
+package eval
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	"bitbucket.org/fflo/semix/pkg/gold"
+	"bitbucket.org/fflo/semix/pkg/semix"
+)
+
+// Names of the different categories of matches.
+const (
+	All         = "all"
+	Direct      = "direct"
+	Fuzzy       = "fuzzy"
+	Ambiguous   = "ambiguous"
+	Unambiguous = "unambiguous"
+)
+
+// Categories lists the names of all categories in report order.
+var Categories = []string{All, Direct, Fuzzy, Ambiguous, Unambiguous}
+
+// Match is a matched span of a document. URL is the URL of the
+// matched concept or the empty string if the ambiguity of the match
+// could not be resolved. Fuzzy marks matches with errors and
+// Ambiguous marks matches of ambiguous concepts.
+type Match struct {
+	Begin, End       int
+	URL              string
+	Fuzzy, Ambiguous bool
+}
+
+func (m Match) categories() []string {
+	cs := []string{All, Direct, Unambiguous}
+	if m.Fuzzy {
+		cs[1] = Fuzzy
+	}
+	if m.Ambiguous {
+		cs[2] = Ambiguous
+	}
+	return cs
+}
+
+// Counts holds the number of true positives,
+// false positives and false negatives.
+type Counts struct {
+	TP, FP, FN int
+}
+
+// Precision returns the precision.
+func (c Counts) Precision() float64 {
+	return ratio(c.TP, c.TP+c.FP)
+}
+
+// Recall returns the recall.
+func (c Counts) Recall() float64 {
+	return ratio(c.TP, c.TP+c.FN)
+}
+
+// F1 returns the harmonic mean of precision and recall.
+func (c Counts) F1() float64 {
+	p, r := c.Precision(), c.Recall()
+	if p+r == 0 {
+		return 0
+	}
+	return 2 * p * r / (p + r)
+}
+
+func ratio(a, b int) float64 {
+	if b == 0 {
+		return 0
+	}
+	return float64(a) / float64(b)
+}
+
+// Scores maps the categories to their counts.
+type Scores map[string]Counts
+
+func (s Scores) add(cats []string, tp, fp, fn int) {
+	for _, cat := range cats {
+		c := s[cat]
+		c.TP += tp
+		c.FP += fp
+		c.FN += fn
+		s[cat] = c
+	}
+}
+
+// Result holds the scores for exact and overlapping spans.
+type Result struct {
+	Exact, Overlap Scores
+}
+
+// NewResult returns a new empty result.
+func NewResult() Result {
+	return Result{Exact: make(Scores), Overlap: make(Scores)}
+}
+
+// Add adds the counts of another result.
+func (r Result) Add(o Result) {
+	for cat, c := range o.Exact {
+		r.Exact.add([]string{cat}, c.TP, c.FP, c.FN)
+	}
+	for cat, c := range o.Overlap {
+		r.Overlap.add([]string{cat}, c.TP, c.FP, c.FN)
+	}
+}
+
+// Evaluate compares the matches of a document with its gold annotations.
+// A match is correct if its URL is the URL of an annotation with the same
+// span (exact) or an overlapping span (overlap). Every annotation can be
+// matched only once. Missed annotations are counted in the categories of
+// the first match that overlaps with them. Missed annotations without any
+// overlapping match are only counted in the category all.
+func Evaluate(as []gold.Annotation, ms []Match) Result {
+	ms = append([]Match(nil), ms...)
+	sort.SliceStable(ms, func(i, j int) bool { return ms[i].Begin < ms[j].Begin })
+	return Result{
+		Exact: evaluate(as, ms, func(a gold.Annotation, m Match) bool {
+			return a.Begin == m.Begin && a.End == m.End
+		}),
+		Overlap: evaluate(as, ms, overlaps),
+	}
+}
+
+func evaluate(as []gold.Annotation, ms []Match, match func(gold.Annotation, Match) bool) Scores {
+	scores := make(Scores)
+	used := make([]bool, len(as))
+	for _, m := range ms {
+		tp := false
+		for i, a := range as {
+			if !used[i] && m.URL != "" && m.URL == a.URL && match(a, m) {
+				used[i], tp = true, true
+				break
+			}
+		}
+		if tp {
+			scores.add(m.categories(), 1, 0, 0)
+		} else {
+			scores.add(m.categories(), 0, 1, 0)
+		}
+	}
+	for i, a := range as {
+		if used[i] {
+			continue
+		}
+		cats := []string{All}
+		for _, m := range ms {
+			if overlaps(a, m) {
+				cats = m.categories()
+				break
+			}
+		}
+		scores.add(cats, 0, 0, 1)
+	}
+	return scores
+}
+
+func overlaps(a gold.Annotation, m Match) bool {
+	return a.Begin < m.End && m.Begin < a.End
+}
+
+// Recorder records the matched concepts of a stream before
+// their ambiguities are resolved.
+type Recorder struct {
+	mutex    sync.Mutex
+	concepts map[span]*semix.Concept
+}
+
+type span struct {
+	path       string
+	begin, end int
+}
+
+// NewRecorder creates a new recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{concepts: make(map[span]*semix.Concept)}
+}
+
+// Record records the concepts of the matched tokens of the stream.
+func (r *Recorder) Record(ctx context.Context, s semix.Stream) semix.Stream {
+	rstream := make(chan semix.StreamToken)
+	go func() {
+		defer close(rstream)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case t, ok := <-s:
+				if !ok {
+					return
+				}
+				if t.Err == nil && t.Token.Concept != nil {
+					r.mutex.Lock()
+					r.concepts[span{t.Token.Path, t.Token.Begin, t.Token.End}] = t.Token.Concept
+					r.mutex.Unlock()
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case rstream <- t:
+				}
+			}
+		}
+	}()
+	return rstream
+}
+
+// Collect reads all tokens of the (resolved) stream and returns
+// the matches of the stream. The classes of the matches are
+// determined by the recorded concepts.
+func (r *Recorder) Collect(s semix.Stream) ([]Match, error) {
+	var ms []Match
+	for t := range s {
+		if t.Err != nil {
+			return nil, t.Err
+		}
+		if t.Token.Concept == nil {
+			continue
+		}
+		ms = append(ms, r.match(t.Token))
+	}
+	return ms, nil
+}
+
+func (r *Recorder) match(t semix.Token) Match {
+	r.mutex.Lock()
+	orig, ok := r.concepts[span{t.Path, t.Begin, t.End}]
+	r.mutex.Unlock()
+	if !ok {
+		orig = t.Concept
+	}
+	begin, end := t.Original()
+	m := Match{Begin: begin, End: end}
+	if !t.Concept.Ambig() {
+		m.URL = t.Concept.URL()
+	}
+	if !orig.Ambig() {
+		return m
+	}
+	senses := make(map[string]int)
+	orig.EachEdge(func(e semix.Edge) {
+		if e.O.Ambig() {
+			e.O.EachEdge(func(f semix.Edge) {
+				addSense(senses, f.O.URL(), e.L+f.L)
+			})
+			return
+		}
+		addSense(senses, e.O.URL(), e.L)
+	})
+	m.Ambiguous = len(senses) > 1
+	if l, ok := senses[m.URL]; ok {
+		m.Fuzzy = l > 0
+		return m
+	}
+	m.Fuzzy = true
+	for _, l := range senses {
+		if l <= 0 {
+			m.Fuzzy = false
+		}
+	}
+	return m
+}
+
+// addSense adds a sense with its minimal error.
+func addSense(senses map[string]int, url string, l int) {
+	if old, ok := senses[url]; !ok || l < old {
+		senses[url] = l
+	}
+}
+
+// WriteText writes the result as a text table.
+func WriteText(w io.Writer, r Result) error {
+	if _, err := fmt.Fprintf(w, "%-12s %-8s %6s %6s %6s %6s %6s %6s\n",
+		"category", "spans", "P", "R", "F1", "TP", "FP", "FN"); err != nil {
+		return err
+	}
+	for _, cat := range Categories {
+		for _, x := range []struct {
+			name   string
+			scores Scores
+		}{{"exact", r.Exact}, {"overlap", r.Overlap}} {
+			c := x.scores[cat]
+			if _, err := fmt.Fprintf(w, "%-12s %-8s %6.4f %6.4f %6.4f %6d %6d %6d\n",
+				cat, x.name, c.Precision(), c.Recall(), c.F1(), c.TP, c.FP, c.FN); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
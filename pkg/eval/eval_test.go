@@ -0,0 +1,157 @@
+package eval
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"bitbucket.org/fflo/semix/pkg/gold"
+	"bitbucket.org/fflo/semix/pkg/semix"
+)
+
+func TestEvaluate(t *testing.T) {
+	as := []gold.Annotation{
+		{Begin: 4, End: 8, URL: "river-bank"},
+		{Begin: 34, End: 38, URL: "financial-bank"},
+		{Begin: 40, End: 44, URL: "city"},
+		{Begin: 50, End: 55, URL: "river"},
+	}
+	ms := []Match{
+		{Begin: 4, End: 8, URL: "river-bank", Ambiguous: true},
+		{Begin: 34, End: 40, URL: "financial-bank", Ambiguous: true, Fuzzy: true},
+		{Begin: 40, End: 44, URL: "town"},
+		{Begin: 60, End: 65, URL: "lake"},
+	}
+	r := Evaluate(as, ms)
+	tests := []struct {
+		name   string
+		scores Scores
+		cat    string
+		want   Counts
+	}{
+		{"exact", r.Exact, All, Counts{TP: 1, FP: 3, FN: 3}},
+		{"exact", r.Exact, Direct, Counts{TP: 1, FP: 2, FN: 1}},
+		{"exact", r.Exact, Fuzzy, Counts{TP: 0, FP: 1, FN: 1}},
+		{"exact", r.Exact, Ambiguous, Counts{TP: 1, FP: 1, FN: 1}},
+		{"exact", r.Exact, Unambiguous, Counts{TP: 0, FP: 2, FN: 1}},
+		{"overlap", r.Overlap, All, Counts{TP: 2, FP: 2, FN: 2}},
+		{"overlap", r.Overlap, Fuzzy, Counts{TP: 1, FP: 0, FN: 0}},
+		{"overlap", r.Overlap, Unambiguous, Counts{TP: 0, FP: 2, FN: 1}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name+" "+tc.cat, func(t *testing.T) {
+			if got := tc.scores[tc.cat]; got != tc.want {
+				t.Fatalf("expected %v; got %v", tc.want, got)
+			}
+		})
+	}
+	total := NewResult()
+	total.Add(r)
+	total.Add(r)
+	if got, want := total.Overlap[All], (Counts{TP: 4, FP: 4, FN: 4}); got != want {
+		t.Fatalf("expected %v; got %v", want, got)
+	}
+}
+
+func TestCounts(t *testing.T) {
+	tests := []struct {
+		test    Counts
+		p, r, f float64
+	}{
+		{Counts{}, 0, 0, 0},
+		{Counts{TP: 1}, 1, 1, 1},
+		{Counts{TP: 1, FP: 1}, 0.5, 1, 2.0 / 3.0},
+		{Counts{TP: 1, FP: 1, FN: 3}, 0.5, 0.25, 1.0 / 3.0},
+	}
+	for _, tc := range tests {
+		if p, r, f := tc.test.Precision(), tc.test.Recall(), tc.test.F1(); p != tc.p || r != tc.r || f != tc.f {
+			t.Fatalf("expected %g %g %g; got %g %g %g", tc.p, tc.r, tc.f, p, r, f)
+		}
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	g := semix.NewGraph()
+	a, _, _ := g.Add("A", "p", "x")
+	b, _, _ := g.Add("B", "p", "x")
+	split := semix.NewConcept(semix.SplitURL)
+	ambig := semix.NewConcept("A-B", semix.WithEdges(split, a, split, b))
+	fuzzy := semix.FuzzyDFAMatcher{
+		DFA: semix.NewFuzzyDFA(1, semix.NewDFA(semix.Dictionary{"bank": a.ID()}, g)),
+	}.Match(" bamk ").Concept
+	if fuzzy == nil {
+		t.Fatalf("cannot match fuzzy concept")
+	}
+	tests := []struct {
+		orig, resolved *semix.Concept
+		want           Match
+	}{
+		{a, a, Match{URL: "A"}},
+		{ambig, a, Match{URL: "A", Ambiguous: true}},
+		{ambig, ambig, Match{Ambiguous: true}},
+		{fuzzy, a, Match{URL: "A", Fuzzy: true}},
+		{fuzzy, fuzzy, Match{Fuzzy: true}},
+	}
+	for i, tc := range tests {
+		t.Run(tc.want.URL, func(t *testing.T) {
+			ctx := context.Background()
+			s := make(chan semix.StreamToken, 1)
+			s <- semix.StreamToken{Token: semix.Token{Path: "test", Begin: i, End: i + 1, Concept: tc.orig}}
+			close(s)
+			r := NewRecorder()
+			resolved := make(chan semix.StreamToken)
+			go func() {
+				defer close(resolved)
+				for t := range r.Record(ctx, s) {
+					t.Token.Concept = tc.resolved
+					resolved <- t
+				}
+			}()
+			ms, err := r.Collect(resolved)
+			if err != nil {
+				t.Fatalf("got error: %s", err)
+			}
+			tc.want.Begin, tc.want.End = i, i+1
+			if len(ms) != 1 || ms[0] != tc.want {
+				t.Fatalf("expected %v; got %v", tc.want, ms)
+			}
+		})
+	}
+}
+
+func TestOriginalOffsets(t *testing.T) {
+	g := semix.NewGraph()
+	a, _, _ := g.Add("A", "p", "x")
+	dfa := semix.NewDFA(semix.Dictionary{"river bank": a.ID()}, g)
+	const text = "down, by   the river  bank!"
+	as := []gold.Annotation{{Begin: 15, End: 26, URL: "A"}}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r := NewRecorder()
+	s := semix.Match(ctx, semix.DFAMatcher{DFA: dfa},
+		semix.Normalizer{}.Normalize(ctx, semix.Read(ctx, semix.NewStringDocument("test", text))))
+	ms, err := r.Collect(r.Record(ctx, s))
+	if err != nil {
+		t.Fatalf("got error: %s", err)
+	}
+	res := Evaluate(as, ms)
+	if got := res.Exact[All]; got != (Counts{TP: 1}) {
+		t.Fatalf("expected one exact match; got %v (matches: %v)", got, ms)
+	}
+}
+
+func TestWriteText(t *testing.T) {
+	var buf bytes.Buffer
+	r := Evaluate([]gold.Annotation{{Begin: 0, End: 1, URL: "A"}}, []Match{{Begin: 0, End: 1, URL: "A"}})
+	if err := WriteText(&buf, r); err != nil {
+		t.Fatalf("got error: %s", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2*len(Categories)+1 {
+		t.Fatalf("invalid number of lines: %d", len(lines))
+	}
+	if want := "all          exact    1.0000 1.0000 1.0000      1      0      0"; lines[1] != want {
+		t.Fatalf("expected %q; got %q", want, lines[1])
+	}
+}
@@ -0,0 +1,94 @@
+// Package gold defines gold standard annotations of documents.
+// The annotations are stored as standoff annotations in JSON files:
+//
+//	{
+//		"path": "document.txt",
+//		"annotations": [
+//			{"begin": 10, "end": 15, "url": "http://example.org/concept"}
+//		]
+//	}
+//
+// Relative document paths are interpreted relative to
+// the directory of the annotation file.
+package gold
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// Annotation marks the text between the byte offsets
+// Begin and End with the URL of its concept.
+type Annotation struct {
+	Begin int    `json:"begin"`
+	End   int    `json:"end"`
+	URL   string `json:"url"`
+}
+
+// Document holds the annotations of the document at Path.
+// The annotations are sorted by their offsets.
+type Document struct {
+	Path        string       `json:"path"`
+	Annotations []Annotation `json:"annotations"`
+	// ends[i] holds the maximal end of the annotations 0..i.
+	ends []int
+}
+
+// Read reads an annotated document from a JSON file.
+func Read(path string) (*Document, error) {
+	is, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = is.Close() }()
+	var d Document
+	if err := json.NewDecoder(is).Decode(&d); err != nil {
+		return nil, errors.Wrapf(err, "cannot read gold file: %s", path)
+	}
+	if d.Path == "" {
+		return nil, errors.Errorf("missing document path: %s", path)
+	}
+	if !filepath.IsAbs(d.Path) {
+		d.Path = filepath.Join(filepath.Dir(path), d.Path)
+	}
+	sort.Slice(d.Annotations, func(i, j int) bool {
+		if d.Annotations[i].Begin == d.Annotations[j].Begin {
+			return d.Annotations[i].End < d.Annotations[j].End
+		}
+		return d.Annotations[i].Begin < d.Annotations[j].Begin
+	})
+	d.index()
+	return &d, nil
+}
+
+// Find returns the first annotation that overlaps
+// with the text between begin and end.
+func (d *Document) Find(begin, end int) (Annotation, bool) {
+	if len(d.ends) != len(d.Annotations) {
+		d.index()
+	}
+	// all annotations before i end before begin
+	i := sort.Search(len(d.ends), func(i int) bool {
+		return d.ends[i] > begin
+	})
+	for ; i < len(d.Annotations) && d.Annotations[i].Begin < end; i++ {
+		if d.Annotations[i].End > begin {
+			return d.Annotations[i], true
+		}
+	}
+	return Annotation{}, false
+}
+
+func (d *Document) index() {
+	d.ends = make([]int, len(d.Annotations))
+	for i, a := range d.Annotations {
+		d.ends[i] = a.End
+		if i > 0 && d.ends[i-1] > a.End {
+			d.ends[i] = d.ends[i-1]
+		}
+	}
+}
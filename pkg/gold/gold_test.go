@@ -0,0 +1,74 @@
+package gold
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+func TestRead(t *testing.T) {
+	d, err := Read("testdata/test.json")
+	if err != nil {
+		t.Fatalf("got error: %s", err)
+	}
+	if got, want := d.Path, filepath.Join("testdata", "test.txt"); got != want {
+		t.Fatalf("expected %s; got %s", want, got)
+	}
+	if len(d.Annotations) != 2 || d.Annotations[0].Begin != 4 {
+		t.Fatalf("invalid annotations: %v", d.Annotations)
+	}
+}
+
+func TestFind(t *testing.T) {
+	d, err := Read("testdata/test.json")
+	if err != nil {
+		t.Fatalf("got error: %s", err)
+	}
+	tests := []struct {
+		begin, end int
+		want       string
+	}{
+		{4, 8, "http://example.org/river-bank"},
+		{0, 5, "http://example.org/river-bank"},
+		{7, 10, "http://example.org/river-bank"},
+		{8, 34, ""},
+		{0, 4, ""},
+		{30, 40, "http://example.org/financial-bank"},
+		{38, 40, ""},
+	}
+	for _, tc := range tests {
+		t.Run(fmt.Sprintf("%d-%d", tc.begin, tc.end), func(t *testing.T) {
+			a, _ := d.Find(tc.begin, tc.end)
+			if a.URL != tc.want {
+				t.Fatalf("expected %q; got %q", tc.want, a.URL)
+			}
+		})
+	}
+}
+
+func TestFindNested(t *testing.T) {
+	d := &Document{Annotations: []Annotation{
+		{Begin: 0, End: 20, URL: "a"},
+		{Begin: 2, End: 5, URL: "b"},
+		{Begin: 10, End: 12, URL: "c"},
+		{Begin: 25, End: 30, URL: "d"},
+	}}
+	tests := []struct {
+		begin, end int
+		want       string
+	}{
+		{13, 15, "a"},
+		{20, 25, ""},
+		{19, 26, "a"},
+		{22, 26, "d"},
+		{30, 32, ""},
+	}
+	for _, tc := range tests {
+		t.Run(fmt.Sprintf("%d-%d", tc.begin, tc.end), func(t *testing.T) {
+			a, _ := d.Find(tc.begin, tc.end)
+			if a.URL != tc.want {
+				t.Fatalf("expected %q; got %q", tc.want, a.URL)
+			}
+		})
+	}
+}
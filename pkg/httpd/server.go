@@ -145,11 +145,16 @@ func (s *Server) ctx(r *http.Request) (*template.Template, interface{}, status)
 	var data struct {
 		URL     string
 		B, E, N int
+		O       bool
 	}
 	if err := rest.DecodeQuery(r.URL.Query(), &data); err != nil {
 		return nil, nil, internalError(err)
 	}
-	ctx, err := s.newClient().Ctx(data.URL, data.B, data.E, data.N)
+	ctxfunc := s.newClient().Ctx
+	if data.O {
+		ctxfunc = s.newClient().OriginalCtx
+	}
+	ctx, err := ctxfunc(data.URL, data.B, data.E, data.N)
 	if err != nil {
 		return nil, nil, internalError(err)
 	}
@@ -11,20 +11,40 @@ import "testing"
 // E is the end position
 // R stores the relation id, if entries are direct, their levenshtein distance
 // and their ambiguity
+// N is the name of the resolver
+// W is the score of the resolver
+// C is the number of candidates of the resolver
+// OB is the start position in the original document
+// OE is the end position in the original document
+// V marks variants
+// NE marks nested matches
 type dse struct {
 	S       string
 	P, B, E uint32
 	R       relationID
+	N       string
+	W       float64
+	C       uint32
+	OB, OE  uint32
+	V       bool
+	NE      bool
 }
 
 func newDSE(e Entry, lookup lookupURLsFunc) dse {
 	relID, docID := lookup(e.RelationURL, e.Path)
 	return dse{
-		S: e.Token,
-		P: uint32(docID),
-		B: uint32(e.Begin),
-		E: uint32(e.End),
-		R: newRelationID(relID, e.L, e.Ambiguous, e.RelationURL != ""),
+		S:  e.Token,
+		P:  uint32(docID),
+		B:  uint32(e.Begin),
+		E:  uint32(e.End),
+		R:  newRelationID(relID, e.L, e.Ambiguous, e.RelationURL != ""),
+		N:  e.Resolver,
+		W:  e.Score,
+		C:  uint32(e.Candidates),
+		OB: uint32(e.OriginalBegin),
+		OE: uint32(e.OriginalEnd),
+		V:  e.Variant,
+		NE: e.Nested,
 	}
 }
 
@@ -39,6 +59,14 @@ func (d dse) entry(conceptURL string, lookup lookupIDsFunc) Entry {
 		End:         int(d.E),
 		L:           d.R.Distance(),
 		Ambiguous:   d.R.Ambiguous(),
+		Resolver:    d.N,
+		Score:       d.W,
+		Candidates:  int(d.C),
+
+		OriginalBegin: int(d.OB),
+		OriginalEnd:   int(d.OE),
+		Variant:       d.V,
+		Nested:        d.NE,
 	}
 }
 
@@ -37,6 +37,13 @@ func (d dse) entry(conceptURL string, lookup lookupIDsFunc) Entry {
 
 func testEntries(t *testing.T, a, b Entry) {
 	t.Helper()
+	a.Resolver = b.Resolver
+	a.Score = b.Score
+	a.Candidates = b.Candidates
+	a.OriginalBegin = b.OriginalBegin
+	a.OriginalEnd = b.OriginalEnd
+	a.Variant = b.Variant
+	a.Nested = b.Nested
 	a.Token = b.Token
 	a.Begin = b.Begin
 	a.End = b.End
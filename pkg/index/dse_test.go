@@ -47,14 +47,25 @@ func testDSELookupURLsFunc(relID, docID int) (string, string) {
 // 	ConceptURL, Path, RelationURL, Token string
 // 	Begin, End, L                        int
 // 	Ambiguous                            bool
+// 	Resolver                             string
+// 	Score                                float64
+// 	Candidates                           int
+// 	OriginalBegin, OriginalEnd           int
+// 	Variant, Nested                      bool
 // }
 func TestDSE(t *testing.T) {
 	tests := []Entry{
-		{"T", "", "", "", 0, 0, 0, false},
-		{"T", "B", "A", "test-token", 1, 7, 1, false},
-		{"T", "C", "B", "test-token", 2, 8, 2, true},
-		{"T", "A", "C", "test-token", 3, 9, 3, false},
-		{"T", "A", "", "test-token", 4, 10, 4, true},
+		{"T", "", "", "", 0, 0, 0, false, "", 0, 0, 0, 0, false, false},
+		{"T", "B", "A", "test-token", 1, 7, 1, false, "", 0, 0, 0, 0, false, false},
+		{"T", "C", "B", "test-token", 2, 8, 2, true, "", 0, 0, 0, 0, false, false},
+		{"T", "A", "C", "test-token", 3, 9, 3, false, "", 0, 0, 0, 0, false, false},
+		{"T", "A", "", "test-token", 4, 10, 4, true, "", 0, 0, 0, 0, false, false},
+		{"T", "A", "", "test-token", 5, 11, 0, false, "thematic", 0.75, 2, 0, 0, false, false},
+		{"T", "A", "B", "test-token", 6, 12, 0, false, "ruled", 1, 3, 0, 0, false, false},
+		{"T", "A", "", "test-token", 7, 13, 0, false, "", 0, 0, 5, 12, false, false},
+		{"T", "A", "", "test-token", 8, 14, 0, false, "", 0, 0, 0, 0, true, true},
+		{"T", "A", "", "test-token", 9, 15, 0, false, "", 0, 0, 0, 0, true, false},
+		{"T", "B", "", "test-token", 10, 16, 0, true, "ruled", 1, 2, 3, 9, true, false},
 	}
 	for _, tc := range tests {
 		t.Run(fmt.Sprintf("%v", tc), func(t *testing.T) {
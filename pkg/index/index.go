@@ -7,11 +7,27 @@ import (
 	"bitbucket.org/fflo/semix/pkg/semix"
 )
 
-// Entry denotes a public available index entry
+// Entry denotes a public available index entry.
+// L is the weighted edit cost of approximate matches (see semix.EditCosts).
 type Entry struct {
 	ConceptURL, Path, RelationURL, Token string
 	Begin, End, L                        int
 	Ambiguous                            bool
+	// Resolver, Score and Candidates record the disambiguation
+	// of the entry's token (see semix.Token).
+	Resolver   string  `json:",omitempty"`
+	Score      float64 `json:",omitempty"`
+	Candidates int     `json:",omitempty"`
+	// OriginalBegin and OriginalEnd are the positions of the entry's
+	// token in the original document. Begin and End are the positions
+	// in the normalized document.
+	OriginalBegin, OriginalEnd int
+	// Variant marks entries that were matched using a
+	// generated variant of a label (see semix.WithVariants).
+	// Nested marks entries that are nested in or overlap
+	// with a maximal match (see semix.MatchAll).
+	Variant bool `json:",omitempty"`
+	Nested  bool `json:",omitempty"`
 }
 
 // Direct returns true iff the entry represents a direct index entry.
@@ -19,6 +35,13 @@ func (e Entry) Direct() bool {
 	return e.RelationURL == ""
 }
 
+// HasOriginal returns true iff the entry records the positions
+// of its token in the original document. Entries that were
+// written by older versions have no original positions.
+func (e Entry) HasOriginal() bool {
+	return e.OriginalEnd > 0
+}
+
 // Putter represents a simple interface to put tokens into an index.
 type Putter interface {
 	Put(semix.Token) error
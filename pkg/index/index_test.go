@@ -49,6 +49,31 @@ func TestIndex(t *testing.T) {
 	}
 }
 
+func TestIndexProvenance(t *testing.T) {
+	g := semix.NewGraph()
+	b, _, _ := g.Add("B", "P", "C")
+	i := NewMemory(2)
+	if err := i.Put(semix.Token{Token: "b", Path: "test", Concept: b,
+		Resolver: "thematic", Score: 0.75, Candidates: 2}); err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	for _, url := range []string{"B", "C"} {
+		var es []Entry
+		if err := i.Get(url, func(e Entry) bool {
+			es = append(es, e)
+			return true
+		}); err != nil {
+			t.Fatalf("got error: %v", err)
+		}
+		if len(es) != 1 {
+			t.Fatalf("expected 1 entry for %s; got %d", url, len(es))
+		}
+		if es[0].Resolver != "thematic" || es[0].Score != 0.75 || es[0].Candidates != 2 {
+			t.Fatalf("invalid provenance for %s: %v", url, es[0])
+		}
+	}
+}
+
 func count(i Interface, url string) int {
 	var count int
 	i.Get(url, func(e Entry) bool {
@@ -73,3 +98,18 @@ func matcher() semix.Matcher {
 	}
 	return semix.DFAMatcher{DFA: semix.NewDFA(d, g)}
 }
+
+func TestEntryHasOriginal(t *testing.T) {
+	tests := []struct {
+		test Entry
+		want bool
+	}{
+		{Entry{Begin: 1, End: 4, OriginalBegin: 0, OriginalEnd: 3}, true},
+		{Entry{Begin: 1, End: 4}, false},
+	}
+	for _, tc := range tests {
+		if got := tc.test.HasOriginal(); got != tc.want {
+			t.Fatalf("%v: expected %t; got %t", tc.test, tc.want, got)
+		}
+	}
+}
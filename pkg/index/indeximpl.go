@@ -147,6 +147,7 @@ func putAll(t semix.Token, f func(Entry) error) error {
 // connected concepts.
 func putAllWithError(t semix.Token, k int, f func(Entry) error) error {
 	url := t.Concept.URL()
+	obegin, oend := t.Original()
 	err := f(Entry{
 		ConceptURL: url,
 		Begin:      t.Begin,
@@ -154,6 +155,14 @@ func putAllWithError(t semix.Token, k int, f func(Entry) error) error {
 		Path:       t.Path,
 		Token:      t.Token,
 		L:          k,
+		Resolver:   t.Resolver,
+		Score:      t.Score,
+		Candidates: t.Candidates,
+
+		OriginalBegin: obegin,
+		OriginalEnd:   oend,
+		Variant:       t.Variant,
+		Nested:        t.Nested,
 	})
 	if err != nil {
 		return err
@@ -170,6 +179,14 @@ func putAllWithError(t semix.Token, k int, f func(Entry) error) error {
 			Token:       t.Token,
 			RelationURL: edge.P.URL(),
 			L:           k,
+			Resolver:    t.Resolver,
+			Score:       t.Score,
+			Candidates:  t.Candidates,
+
+			OriginalBegin: obegin,
+			OriginalEnd:   oend,
+			Variant:       t.Variant,
+			Nested:        t.Nested,
 		})
 		if err != nil {
 			return err
@@ -18,6 +18,9 @@ const (
 )
 
 func newRelationID(id, l int, a, d bool) relationID {
+	if l > int(levflag) { // do not overflow into the flags
+		l = int(levflag)
+	}
 	x := relationID(id) & idflag
 	x |= (relationID(l) & levflag) << levshift
 	if a {
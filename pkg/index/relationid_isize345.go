@@ -15,6 +15,9 @@ const (
 )
 
 func newRelationID(l int, a, d bool) relationID {
+	if l > int(levflag) { // do not overflow into the flags
+		l = int(levflag)
+	}
 	x := relationID(l) & levflag
 	if a {
 		x |= aflag
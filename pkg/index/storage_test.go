@@ -33,13 +33,16 @@ func TestStorage(t *testing.T) {
 	}{
 		{"empty", []Entry{}},
 		{"url1", []Entry{
-			{"url1", "path1", "", "token1", 8, 10, 5, false},
-			{"url1", "path2", "rel1", "token4", 8, 10, 5, true},
-			{"url1", "path3", "rel2", "token3", 8, 12, 0, true},
+			{"url1", "path1", "", "token1", 8, 10, 5, false, "", 0, 0, 0, 0, false, false},
+			{"url1", "path2", "rel1", "token4", 8, 10, 5, true, "", 0, 0, 0, 0, false, false},
+			{"url1", "path3", "rel2", "token3", 8, 12, 0, true, "", 0, 0, 0, 0, false, false},
+			{"url1", "path4", "", "token5", 8, 12, 0, false, "bayes", 0.9, 3, 7, 13, false, false},
+			{"url1", "path5", "", "token6", 8, 12, 0, false, "", 0, 0, 0, 0, true, false},
+			{"url1", "path6", "rel1", "token7", 8, 12, 0, true, "ruled", 1, 2, 9, 15, true, true},
 		}},
 		{"url2", []Entry{
-			{"url2", "path1", "", "token1", 8, 10, 5, false},
-			{"url2", "path2", "rel3", "token4", 8, 10, 0, true},
+			{"url2", "path1", "", "token1", 8, 10, 5, false, "", 0, 0, 0, 0, false, false},
+			{"url2", "path2", "rel3", "token4", 8, 10, 0, true, "", 0, 0, 0, 0, false, false},
 		}},
 	}
 	dir := openTmpdir()
@@ -0,0 +1,51 @@
+package memory
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Names of the different decay types.
+const (
+	// NoDecay gives every concept the weight 1.
+	NoDecay = ""
+	// ExponentialDecay weights concepts with exp(-rate*distance).
+	ExponentialDecay = "exponential"
+	// LinearDecay weights concepts with max(0, 1-rate*distance).
+	LinearDecay = "linear"
+)
+
+// Decay defines how the weight of a concept in the memory decays
+// with its distance. The distance is measured in tokens
+// or, if Offsets is set, in characters.
+type Decay struct {
+	Type    string
+	Rate    float64
+	Offsets bool
+}
+
+// NewDecay creates a new decay and checks its type.
+// The type "none" is an alias for NoDecay.
+func NewDecay(typ string, rate float64, offsets bool) (Decay, error) {
+	d := Decay{Type: strings.ToLower(typ), Rate: rate, Offsets: offsets}
+	if d.Type == "none" {
+		d.Type = NoDecay
+	}
+	switch d.Type {
+	case NoDecay, ExponentialDecay, LinearDecay:
+		return d, nil
+	}
+	return Decay{}, fmt.Errorf("invalid decay: %s", typ)
+}
+
+// Weight returns the weight for the given distance.
+func (d Decay) Weight(dist float64) float64 {
+	switch d.Type {
+	case ExponentialDecay:
+		return math.Exp(-d.Rate * dist)
+	case LinearDecay:
+		return math.Max(0, 1-d.Rate*dist)
+	}
+	return 1
+}
@@ -1,32 +1,89 @@
 package memory
 
 import (
+	"math"
+
 	"bitbucket.org/fflo/semix/pkg/semix"
 )
 
 // Memory is simple ringbuffer that enables counting of concepts stored
-// in the memory.
+// in the memory. Each concept in the memory is stored with its
+// position. The weight of a concept is calculated from the distance
+// of its position to the current position of the memory using
+// the memory's decay.
 type Memory struct {
 	buffer []*semix.Concept
+	pos    []int
+	decay  Decay
 	i      uint
 	end    int
+	now    int
 }
 
 // New creates a new Memory with a fixed size.
+// All concepts in the memory have the weight 1.
 func New(n int) *Memory {
-	return &Memory{buffer: make([]*semix.Concept, n)}
+	return NewWeighted(n, Decay{})
+}
+
+// NewWeighted creates a new Memory with a fixed size
+// that weights its concepts using the given decay.
+func NewWeighted(n int, d Decay) *Memory {
+	return &Memory{
+		buffer: make([]*semix.Concept, n),
+		pos:    make([]int, n),
+		decay:  d,
+	}
 }
 
 // Push pushes a new concept into the memory.
 // It removes the last inserted concept.
+// The concept is pushed at the current position
+// and the current position is advanced by one.
 func (m *Memory) Push(c *semix.Concept) {
+	m.PushAt(c, m.now)
+	m.now++
+}
+
+// PushAt pushes a new concept at the given position into the memory.
+// It removes the last inserted concept.
+// The current position of the memory is not changed.
+func (m *Memory) PushAt(c *semix.Concept, pos int) {
 	if m.end < len(m.buffer) {
 		m.end++
 	}
 	m.buffer[m.i] = c
+	m.pos[m.i] = pos
 	m.i = inc(m.i, len(m.buffer))
 }
 
+// At sets the current position of the memory.
+func (m *Memory) At(pos int) {
+	m.now = pos
+}
+
+// Pos returns the current position of the memory.
+func (m *Memory) Pos() int {
+	return m.now
+}
+
+// Extend returns a copy of the memory with room for n additional
+// concepts. The copy has the same decay and current position
+// and contains the concepts of the memory in insertion order.
+func (m Memory) Extend(n int) *Memory {
+	e := NewWeighted(len(m.buffer)+n, m.decay)
+	e.now = m.now
+	start := 0
+	if m.end == len(m.buffer) {
+		start = int(m.i)
+	}
+	for j := 0; j < m.end; j++ {
+		i := (start + j) % len(m.buffer)
+		e.PushAt(m.buffer[i], m.pos[i])
+	}
+	return e
+}
+
 // Each calls a callback function for each concept in the memory.
 func (m Memory) Each(f func(*semix.Concept)) {
 	for i := 0; i < m.end; i++ {
@@ -45,6 +102,33 @@ func (m Memory) EachS(f func(*semix.Concept)) {
 	}
 }
 
+// EachW calls a callback function for each concept in the memory
+// and its weight.
+func (m Memory) EachW(f func(*semix.Concept, float64)) {
+	for i := 0; i < m.end; i++ {
+		f(m.buffer[i], m.weight(i))
+	}
+}
+
+// EachSW calls a callback function for each concept in the memory and for
+// each concept that is referenced from this concept. Referenced concepts
+// have the same weight as the concept that references them.
+func (m Memory) EachSW(f func(*semix.Concept, float64)) {
+	for i := 0; i < m.end; i++ {
+		w := m.weight(i)
+		f(m.buffer[i], w)
+		m.buffer[i].EachEdge(func(e semix.Edge) {
+			f(e.O, w)
+		})
+	}
+}
+
+// weight returns the weight of the i-th concept. Concepts can lie
+// before or after the current position of the memory.
+func (m Memory) weight(i int) float64 {
+	return m.decay.Weight(math.Abs(float64(m.now - m.pos[i])))
+}
+
 // Elements returns the set of unique concepts in the memory.
 func (m Memory) Elements() map[string]*semix.Concept {
 	set := make(map[string]*semix.Concept)
@@ -87,6 +171,31 @@ func (m Memory) CountIfS(p func(c *semix.Concept) bool) int {
 	return n
 }
 
+// CountIfW returns the sum of the weights of the concepts
+// for wich the given predicate returns true.
+func (m Memory) CountIfW(p func(c *semix.Concept) bool) float64 {
+	var n float64
+	m.EachW(func(c *semix.Concept, w float64) {
+		if p(c) {
+			n += w
+		}
+	})
+	return n
+}
+
+// CountIfSW returns the sum of the weights of the concepts
+// for wich the given predicate returns true.
+// The predicate is called for each concept and referenced concept.
+func (m Memory) CountIfSW(p func(c *semix.Concept) bool) float64 {
+	var n float64
+	m.EachSW(func(c *semix.Concept, w float64) {
+		if p(c) {
+			n += w
+		}
+	})
+	return n
+}
+
 // N returns the maximal number of elements in the memory.
 func (m Memory) N() int {
 	return len(m.buffer)
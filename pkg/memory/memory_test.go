@@ -2,6 +2,7 @@ package memory
 
 import (
 	"fmt"
+	"math"
 	"testing"
 
 	"bitbucket.org/fflo/semix/pkg/semix"
@@ -83,3 +84,103 @@ func TestMemory(t *testing.T) {
 		})
 	}
 }
+
+func TestDecay(t *testing.T) {
+	tests := []struct {
+		typ        string
+		rate, dist float64
+		want       float64
+		iserr      bool
+	}{
+		{"", 0.5, 3, 1, false},
+		{"none", 0.5, 3, 1, false},
+		{"linear", 0.25, 2, 0.5, false},
+		{"Linear", 0.25, 5, 0, false},
+		{"exponential", 0, 7, 1, false},
+		{"exponential", 1, 2, math.Exp(-2), false},
+		{"invalid", 1, 1, 0, true},
+	}
+	for _, tc := range tests {
+		t.Run(fmt.Sprintf("%s-%g-%g", tc.typ, tc.rate, tc.dist), func(t *testing.T) {
+			d, err := NewDecay(tc.typ, tc.rate, false)
+			if tc.iserr {
+				if err == nil {
+					t.Fatalf("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("got error: %s", err)
+			}
+			if got := d.Weight(tc.dist); got != tc.want {
+				t.Fatalf("expected %g; got %g", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestWeightedMemory(t *testing.T) {
+	m := NewWeighted(3, Decay{Type: LinearDecay, Rate: 0.25})
+	pushURLs(m, []string{"A", "B", "A"})
+	// distances: A=3, B=2, A=1
+	if got := m.CountIfW(equalsURL("A")); got != 1 {
+		t.Fatalf("expected %g; got %g", 1.0, got)
+	}
+	if got := m.CountIfW(equalsURL("B")); got != 0.5 {
+		t.Fatalf("expected %g; got %g", 0.5, got)
+	}
+	if got := m.CountIfSW(equalsURL("D")); got != 1.5 {
+		t.Fatalf("expected %g; got %g", 1.5, got)
+	}
+	if got := m.CountIf(equalsURL("A")); got != 2 {
+		t.Fatalf("expected %d; got %d", 2, got)
+	}
+	m.At(10)
+	m.PushAt(semix.NewConcept("B"), 8)
+	// distances: B=2, B=9, A=8
+	if got := m.CountIfW(equalsURL("B")); got != 0.5 {
+		t.Fatalf("expected %g; got %g", 0.5, got)
+	}
+	if got := m.CountIfW(equalsURL("A")); got != 0 {
+		t.Fatalf("expected %g; got %g", 0.0, got)
+	}
+}
+
+func TestUnweightedMemory(t *testing.T) {
+	m := New(5)
+	pushURLs(m, []string{"A", "B", "A", "C", "A"})
+	if got := m.CountIfW(equalsURL("A")); got != 3 {
+		t.Fatalf("expected %g; got %g", 3.0, got)
+	}
+	if got := m.CountIfSW(equalsURL("D")); got != 5 {
+		t.Fatalf("expected %g; got %g", 5.0, got)
+	}
+}
+
+func TestExtend(t *testing.T) {
+	m := NewWeighted(3, Decay{Type: LinearDecay, Rate: 0.25})
+	pushURLs(m, []string{"A", "B", "C", "A"})
+	e := m.Extend(2)
+	if got := e.N(); got != 5 {
+		t.Fatalf("expected %d; got %d", 5, got)
+	}
+	if got := e.Pos(); got != m.Pos() {
+		t.Fatalf("expected %d; got %d", m.Pos(), got)
+	}
+	e.PushAt(semix.NewConcept("B"), e.Pos()+1)
+	e.PushAt(semix.NewConcept("B"), e.Pos()+2)
+	// current position is 4: B=1, C=2, A=3, B=5 and B=6
+	// with the distances 3, 2, 1, 1 and 2
+	if got := e.Len(); got != 5 {
+		t.Fatalf("expected %d; got %d", 5, got)
+	}
+	if got := e.CountIfW(equalsURL("B")); got != 1.5 {
+		t.Fatalf("expected %g; got %g", 1.5, got)
+	}
+	if got := e.CountIfW(equalsURL("A")); got != 0.75 {
+		t.Fatalf("expected %g; got %g", 0.75, got)
+	}
+	if got := m.Len(); got != 3 {
+		t.Fatalf("original memory changed: expected %d; got %d", 3, got)
+	}
+}
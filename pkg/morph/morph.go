@@ -0,0 +1,192 @@
+// Package morph generates inflected variants of dictionary labels
+// using simple suffix rules. A rule strip/add replaces the suffix
+// strip of the last word of a label with add. For example, the rule
+// e/en generates the variant Straßen for the label Straße and the
+// rule /s generates the variant Autos for the label Auto.
+//
+// A rule strip/add/cond only applies to words that end with a match
+// of the regular expression cond. If cond starts with !, the rule only
+// applies to words that do not end with a match. For example, the rule
+// y/ies/[^aeiou]y generates the variant cities for the label city but
+// no variant for the label day.
+package morph
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DefaultMinStem is the default minimal number of
+// characters of a stem that remain after stripping.
+const DefaultMinStem = 3
+
+var builtin = map[string][]string{
+	"de": {"/e", "/en", "/n", "/s", "/es", "/er", "/ern", "/ens", "/em"},
+	"en": {"/s/!(s|x|z|ch|sh|[^aeiou]y)", "/es/(s|x|z|ch|sh)", "y/ies/[^aeiou]y"},
+	"fr": {"/s", "/x", "al/aux", "/e", "/es"},
+}
+
+// Languages returns the sorted names of the languages
+// with builtin rule sets.
+func Languages() []string {
+	langs := make([]string, 0, len(builtin))
+	for lang := range builtin {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// Builtin returns the builtin rule set for the given language.
+func Builtin(lang string) (Rules, error) {
+	strs, ok := builtin[strings.ToLower(lang)]
+	if !ok {
+		return nil, fmt.Errorf("no rules for language: %s", lang)
+	}
+	return ParseRules(strs...)
+}
+
+// Rule is a suffix rule that replaces the suffix Strip with Add.
+// If Cond is not empty, the rule only applies to words that
+// end (or with a leading ! do not end) with a match of Cond.
+type Rule struct {
+	Strip, Add, Cond string
+	cond             *regexp.Regexp
+}
+
+// ParseRule parses a rule of the form strip/add or strip/add/cond.
+func ParseRule(str string) (Rule, error) {
+	fs := strings.SplitN(str, "/", 3)
+	if len(fs) < 2 {
+		return Rule{}, fmt.Errorf("invalid rule %q: missing /", str)
+	}
+	r := Rule{Strip: fs[0], Add: fs[1]}
+	if r.Strip == r.Add {
+		return Rule{}, fmt.Errorf("invalid rule %q: identity", str)
+	}
+	if len(fs) == 3 {
+		r.Cond = fs[2]
+		re, err := regexp.Compile("(?:" + strings.TrimPrefix(r.Cond, "!") + ")$")
+		if err != nil || r.Cond == "" || r.Cond == "!" {
+			return Rule{}, fmt.Errorf("invalid rule %q: invalid condition", str)
+		}
+		r.cond = re
+	}
+	return r, nil
+}
+
+// String returns the string representation strip/add[/cond] of the rule.
+func (r Rule) String() string {
+	if r.Cond != "" {
+		return r.Strip + "/" + r.Add + "/" + r.Cond
+	}
+	return r.Strip + "/" + r.Add
+}
+
+// applies returns true if the rule applies to the given word.
+func (r Rule) applies(word string) bool {
+	if !strings.HasSuffix(word, r.Strip) {
+		return false
+	}
+	if r.cond == nil {
+		return true
+	}
+	return r.cond.MatchString(word) != strings.HasPrefix(r.Cond, "!")
+}
+
+// Rules is a list of suffix rules.
+type Rules []Rule
+
+// ParseRules parses a list of rules.
+func ParseRules(strs ...string) (Rules, error) {
+	rs := make(Rules, 0, len(strs))
+	for _, str := range strs {
+		r, err := ParseRule(str)
+		if err != nil {
+			return nil, err
+		}
+		rs = append(rs, r)
+	}
+	return rs, nil
+}
+
+// Generator generates the variants of labels.
+type Generator struct {
+	langs   []string
+	rules   map[string]Rules
+	minStem int
+}
+
+// NewGenerator creates a new generator that uses the rule
+// sets of the given languages. The stem of a word that
+// remains after stripping must have at least minStem characters.
+func NewGenerator(rules map[string]Rules, minStem int) *Generator {
+	g := &Generator{rules: rules, minStem: minStem}
+	for lang := range rules {
+		g.langs = append(g.langs, lang)
+	}
+	sort.Strings(g.langs)
+	return g
+}
+
+// Variants returns the variants of the given label. Only the last word
+// of the label is inflected. Labels that do not end with a letter have
+// no variants. The label itself is never a variant.
+func (g *Generator) Variants(label string) []string {
+	i := lastWord(label)
+	if i == len(label) {
+		return nil
+	}
+	prefix, word := label[:i], label[i:]
+	set := map[string]bool{label: true}
+	var vs []string
+	for _, lang := range g.langs {
+		for _, r := range g.rules[lang] {
+			if !r.applies(word) {
+				continue
+			}
+			stem := word[:len(word)-len(r.Strip)]
+			if utf8.RuneCountInString(stem) < g.minStem {
+				continue
+			}
+			v := prefix + stem + r.Add
+			if !set[v] {
+				set[v] = true
+				vs = append(vs, v)
+			}
+		}
+	}
+	return vs
+}
+
+// String returns a description of the rule sets of the generator.
+func (g *Generator) String() string {
+	strs := make([]string, 0, len(g.langs)+1)
+	for _, lang := range g.langs {
+		rs := make([]string, len(g.rules[lang]))
+		for i, r := range g.rules[lang] {
+			rs[i] = r.String()
+		}
+		strs = append(strs, lang+":"+strings.Join(rs, ","))
+	}
+	strs = append(strs, fmt.Sprintf("minstem=%d", g.minStem))
+	return strings.Join(strs, " ")
+}
+
+// lastWord returns the start of the last word of the given string.
+// It returns len(str) if the string does not end with a letter.
+func lastWord(str string) int {
+	i := len(str)
+	for i > 0 {
+		r, size := utf8.DecodeLastRuneInString(str[:i])
+		if !unicode.IsLetter(r) {
+			break
+		}
+		i -= size
+	}
+	return i
+}
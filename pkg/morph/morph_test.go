@@ -0,0 +1,101 @@
+package morph
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseRule(t *testing.T) {
+	tests := []struct {
+		test  string
+		want  Rule
+		iserr bool
+	}{
+		{"/s", Rule{Add: "s"}, false},
+		{"y/ies", Rule{Strip: "y", Add: "ies"}, false},
+		{"e/", Rule{Strip: "e"}, false},
+		{"s", Rule{}, true},
+		{"/", Rule{}, true},
+		{"e/e", Rule{}, true},
+		{"/es/(s|x)", Rule{Add: "es", Cond: "(s|x)"}, false},
+		{"/s/!y", Rule{Add: "s", Cond: "!y"}, false},
+		{"/s/(", Rule{}, true},
+		{"/s/", Rule{}, true},
+		{"/s/!", Rule{}, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.test, func(t *testing.T) {
+			got, err := ParseRule(tc.test)
+			if tc.iserr {
+				if err == nil {
+					t.Fatalf("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("got error: %v", err)
+			}
+			if got.Strip != tc.want.Strip || got.Add != tc.want.Add || got.Cond != tc.want.Cond {
+				t.Fatalf("expected %v; got %v", tc.want, got)
+			}
+			if str := got.String(); str != tc.test {
+				t.Fatalf("expected %q; got %q", tc.test, str)
+			}
+		})
+	}
+}
+
+func TestVariants(t *testing.T) {
+	en, err := Builtin("en")
+	if err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	de, err := ParseRules("e/en", "/s")
+	if err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	g := NewGenerator(map[string]Rules{"en": en}, DefaultMinStem)
+	gde := NewGenerator(map[string]Rules{"en": en, "de": de}, DefaultMinStem)
+	tests := []struct {
+		g    *Generator
+		test string
+		want []string
+	}{
+		{g, "city", []string{"cities"}},
+		{g, "big city", []string{"big cities"}},
+		{g, "day", []string{"days"}},
+		{g, "car", []string{"cars"}},
+		{g, "box", []string{"boxes"}},
+		{g, "church", []string{"churches"}},
+		{g, "ox", nil},
+		{g, "x-1", nil},
+		{g, "", nil},
+		{gde, "straße", []string{"straßen", "straßes"}},
+		{gde, "city", []string{"citys", "cities"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.test, func(t *testing.T) {
+			if got := tc.g.Variants(tc.test); !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("expected %q; got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestString(t *testing.T) {
+	rs, err := ParseRules("/s", "y/ies")
+	if err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	g := NewGenerator(map[string]Rules{"en": rs}, 4)
+	if got, want := g.String(), "en:/s,y/ies minstem=4"; got != want {
+		t.Fatalf("expected %q; got %q", want, got)
+	}
+	if _, err := Builtin("xx"); err == nil {
+		t.Fatalf("expected error")
+	}
+	if got := strings.Join(Languages(), ","); got != "de,en,fr" {
+		t.Fatalf("invalid languages: %s", got)
+	}
+}
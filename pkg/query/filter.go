@@ -0,0 +1,101 @@
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"bitbucket.org/fflo/semix/pkg/index"
+)
+
+// filter represents a predicate on the surface form
+// or the Levenshtein distance of index entries.
+type filter interface {
+	fmt.Stringer
+	match(index.Entry) bool
+}
+
+type filters []filter
+
+func (fs filters) String() string {
+	if len(fs) == 0 {
+		return ""
+	}
+	strs := make([]string, len(fs))
+	for i, f := range fs {
+		strs[i] = f.String()
+	}
+	return "[" + strings.Join(strs, ",") + "]"
+}
+
+// match returns true if all filters match the given entry.
+func (fs filters) match(e index.Entry) bool {
+	for _, f := range fs {
+		if !f.match(e) {
+			return false
+		}
+	}
+	return true
+}
+
+// lev returns true if the filters contain a Levenshtein distance filter.
+func (fs filters) lev() bool {
+	for _, f := range fs {
+		if _, ok := f.(levFilter); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// tokenFilter matches entries whose token equals the given string.
+type tokenFilter string
+
+func (f tokenFilter) match(e index.Entry) bool {
+	return e.Token == string(f)
+}
+
+func (f tokenFilter) String() string {
+	return fmt.Sprintf("token=%q", string(f))
+}
+
+// prefixFilter matches entries whose token starts with the given string.
+type prefixFilter string
+
+func (f prefixFilter) match(e index.Entry) bool {
+	return strings.HasPrefix(e.Token, string(f))
+}
+
+func (f prefixFilter) String() string {
+	return fmt.Sprintf("prefix=%q", string(f))
+}
+
+// regexFilter matches entries whose token matches the given regex.
+type regexFilter struct {
+	re *regexp.Regexp
+}
+
+func (f regexFilter) match(e index.Entry) bool {
+	return f.re.MatchString(e.Token)
+}
+
+func (f regexFilter) String() string {
+	return fmt.Sprintf("regex=%q", f.re.String())
+}
+
+// levFilter matches entries whose Levenshtein distance
+// lies in the closed interval [min,max].
+type levFilter struct {
+	min, max int
+}
+
+func (f levFilter) match(e index.Entry) bool {
+	return f.min <= e.L && e.L <= f.max
+}
+
+func (f levFilter) String() string {
+	if f.min == f.max {
+		return fmt.Sprintf("l=%d", f.min)
+	}
+	return fmt.Sprintf("l=%d-%d", f.min, f.max)
+}
@@ -3,6 +3,7 @@ package query
 import (
 	"errors"
 	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
 	"text/scanner"
@@ -44,16 +45,17 @@ func (p *Parser) Parse() (q *Query, err error) {
 
 func (p *Parser) parseQuery() *Query {
 	p.eat('?')
-	k, a := p.parseQueryOpt()
+	k, a, fs := p.parseQueryOpt()
 	p.eat('(')
 	c, s := p.parseQueryExp()
 	p.eat(')')
-	return &Query{set: s, constraint: c, l: k, a: a}
+	return &Query{set: s, constraint: c, filters: fs, l: k, a: a}
 }
 
-func (p *Parser) parseQueryOpt() (int, bool) {
+func (p *Parser) parseQueryOpt() (int, bool, filters) {
 	var k int64
 	var a bool
+	var fs filters
 loop:
 	for {
 		switch l := p.peek(); l {
@@ -63,11 +65,78 @@ loop:
 		case scanner.Int:
 			_, str := p.eat(scanner.Int)
 			k, _ = strconv.ParseInt(str, 10, 32)
+		case '[':
+			fs = append(fs, p.parseFilters()...)
 		default:
 			break loop
 		}
 	}
-	return int(k), a
+	return int(k), a, fs
+}
+
+func (p *Parser) parseFilters() filters {
+	p.eat('[')
+	var fs filters
+	for p.peek() != ']' {
+		fs = append(fs, p.parseFilter())
+		if tok, _ := p.eat(',', ']'); tok == ']' {
+			return fs
+		}
+	}
+	p.eat(']')
+	return fs
+}
+
+func (p *Parser) parseFilter() filter {
+	_, name := p.eat(scanner.Ident)
+	p.eat('=')
+	switch name {
+	case "token":
+		return tokenFilter(p.parseFilterString())
+	case "prefix":
+		return prefixFilter(p.parseFilterString())
+	case "regex":
+		str := p.parseFilterString()
+		re, err := regexp.Compile(str)
+		if err != nil {
+			p.fatalf("invalid regex %q: %s", str, err)
+		}
+		return regexFilter{re: re}
+	case "l":
+		min := p.parseInt()
+		max := min
+		if p.peek() == '-' {
+			p.eat('-')
+			max = p.parseInt()
+		}
+		if min > max {
+			p.fatalf("invalid range: %d-%d", min, max)
+		}
+		return levFilter{min: min, max: max}
+	}
+	p.fatalf("invalid filter: %s", name)
+	panic("unreacheable")
+}
+
+func (p *Parser) parseFilterString() string {
+	tok, str := p.eat(scanner.String, scanner.RawString)
+	if tok == scanner.RawString {
+		return str[1 : len(str)-1]
+	}
+	s, err := strconv.Unquote(str)
+	if err != nil {
+		p.fatalf("cannot parse string: %s", err)
+	}
+	return s
+}
+
+func (p *Parser) parseInt() int {
+	_, str := p.eat(scanner.Int)
+	k, err := strconv.ParseInt(str, 10, 32)
+	if err != nil {
+		p.fatalf("cannot parse integer: %s", err)
+	}
+	return int(k)
 }
 
 func (p *Parser) parseQueryExp() (constraint, set) {
@@ -20,6 +20,18 @@ func TestParser(t *testing.T) {
 		{"?*10(*(C, D))", "?*10(*(C,D))", false},
 		{`?("A"("B","C"))`, `?(A(B,C))`, false},
 		{`?("A B"("C D","E F"))`, `?(A B(C D,E F))`, false},
+		{`?[token="New York"](A)`, `?[token="New York"](A)`, false},
+		{`?[prefix="New"](A)`, `?[prefix="New"](A)`, false},
+		{"?[regex=`^N.*k$`](A)", `?[regex="^N.*k$"](A)`, false},
+		{`?[l=1](A)`, `?[l=1](A)`, false},
+		{`?2*[l=1-2, prefix="Ne"](A)`, `?*2[l=1-2,prefix="Ne"](A)`, false},
+		{`?[l=1][token="x"](A)`, `?[l=1,token="x"](A)`, false},
+		{`?[](A)`, `?(A)`, false},
+		{`?[l=2-1](A)`, "", true},
+		{`?[foo="x"](A)`, "", true},
+		{`?[regex="("](A)`, "", true},
+		{`?[token=1](A)`, "", true},
+		{`?[token="x"`, "", true},
 		{"", "", true},
 		{"?(", "", true},
 		{"?(!*({C, D}))", "", true},
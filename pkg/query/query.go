@@ -17,6 +17,7 @@ type LookupFunc func(string) ([]string, error)
 type Query struct {
 	constraint constraint
 	set        set
+	filters    filters
 	l          int
 	a          bool
 }
@@ -91,8 +92,17 @@ func (q Query) ExecuteFunc(idx index.Interface, f func(index.Entry) bool) error
 	return nil
 }
 
+// match returns true if the entry matches the query.
+// If the query contains a Levenshtein distance filter,
+// the filter replaces the query's default error limit.
 func (q Query) match(e index.Entry) bool {
-	return q.a == e.Ambiguous && e.L <= q.l && q.constraint.match(e)
+	if q.a != e.Ambiguous {
+		return false
+	}
+	if !q.filters.lev() && e.L > q.l {
+		return false
+	}
+	return q.filters.match(e) && q.constraint.match(e)
 }
 
 // String returns a string representing the query.
@@ -104,6 +114,7 @@ func (q Query) String() string {
 	if q.l != 0 {
 		pre += fmt.Sprintf("%d", q.l)
 	}
+	pre += q.filters.String()
 	c := q.constraint.String()
 	if len(c) == 0 {
 		return pre + "(" + q.set.String() + ")"
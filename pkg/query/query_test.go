@@ -86,6 +86,44 @@ func TestQueryExecute(t *testing.T) {
 	}
 }
 
+func TestQueryFilters(t *testing.T) {
+	tests := []struct {
+		query, want string
+		iserr       bool
+	}{
+		{"?(A)", "[New York]", false},
+		{"?2(A)", "[New York Nev York Nev Yrk]", false},
+		{`?2[token="Nev York"](A)`, "[Nev York]", false},
+		{`?2[prefix="Nev"](A)`, "[Nev York Nev Yrk]", false},
+		{"?2[regex=`Yo.*k$`](A)", "[New York Nev York]", false},
+		{"?[l=1-2](A)", "[Nev York Nev Yrk]", false},
+		{"?[l=2](A)", "[Nev Yrk]", false},
+		{`?[l=0-1,prefix="Nev"](A)`, "[Nev York]", false},
+		{`?[token="York"](A)`, "[]", false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.query, func(t *testing.T) {
+			q, err := New(tc.query, func(str string) ([]string, error) {
+				return []string{str}, nil
+			})
+			if err != nil {
+				t.Fatalf("got error: %s", err)
+			}
+			var strs []string
+			err = q.ExecuteFunc(queryFilterTestIndex{}, func(e index.Entry) bool {
+				strs = append(strs, e.Token)
+				return true
+			})
+			if err != nil {
+				t.Fatalf("got error: %s", err)
+			}
+			if got := fmt.Sprintf("%v", strs); got != tc.want {
+				t.Fatalf("expected %q; got %q", tc.want, got)
+			}
+		})
+	}
+}
+
 func tostring(es []index.Entry) string {
 	type pair struct {
 		first, second string
@@ -115,3 +153,15 @@ func (i queryTestIndex) Get(url string, f func(e index.Entry) bool) error {
 	f(index.Entry{ConceptURL: url, RelationURL: "S", L: i.k, Ambiguous: i.a})
 	return i.err
 }
+
+type queryFilterTestIndex struct{}
+
+func (queryFilterTestIndex) Put(semix.Token) error { return nil }
+func (queryFilterTestIndex) Close() error          { return nil }
+func (queryFilterTestIndex) Flush() error          { return nil }
+func (queryFilterTestIndex) Get(url string, f func(e index.Entry) bool) error {
+	f(index.Entry{ConceptURL: url, Token: "New York", L: 0})
+	f(index.Entry{ConceptURL: url, Token: "Nev York", L: 1})
+	f(index.Entry{ConceptURL: url, Token: "Nev Yrk", L: 2})
+	return nil
+}
@@ -6,15 +6,22 @@ import (
 )
 
 // Automatic disambiguates concepts by calculating the thematic overlap
-// of the concepts with the memory.
+// of the concepts with the memory. The overlap is weighted with the
+// weights of the concepts in the memory.
 type Automatic struct {
 	Threshold float64
 }
 
 // Resolve resolves ambiguities using the automatic method.
 func (a Automatic) Resolve(c *semix.Concept, mem *memory.Memory) *semix.Concept {
-	elems := mem.ElementsS()
-	return resolve(c, func(c *semix.Concept) float64 {
+	return best(a.Scores(c, mem))
+}
+
+// Scores returns the thematic overlap of the possible senses with
+// the memory. Overlaps below the threshold are set to 0.
+func (a Automatic) Scores(c *semix.Concept, mem *memory.Memory) []Candidate {
+	elems := weightedElements(mem)
+	return candidates(c, func(c *semix.Concept) float64 {
 		o := overlap(c, elems)
 		if o > a.Threshold {
 			return o
@@ -23,16 +30,26 @@ func (a Automatic) Resolve(c *semix.Concept, mem *memory.Memory) *semix.Concept
 	})
 }
 
-func overlap(c *semix.Concept, elems map[string]*semix.Concept) float64 {
+// weightedElements returns the unique concepts in the memory,
+// including all referenced concepts, mapped to their maximal weight.
+func weightedElements(mem *memory.Memory) map[string]float64 {
+	elems := make(map[string]float64)
+	mem.EachSW(func(c *semix.Concept, w float64) {
+		if old, ok := elems[c.URL()]; !ok || w > old {
+			elems[c.URL()] = w
+		}
+	})
+	return elems
+}
+
+func overlap(c *semix.Concept, elems map[string]float64) float64 {
 	celems := make(map[string]bool)
 	c.EachEdge(func(e semix.Edge) {
 		celems[e.O.URL()] = true
 	})
 	var n float64
 	for url := range celems {
-		if elems[url] != nil {
-			n++
-		}
+		n += elems[url]
 	}
 	return n / float64(len(elems))
 }
@@ -0,0 +1,153 @@
+package resolve
+
+import (
+	"context"
+	"encoding/gob"
+	"io"
+	"math"
+
+	"bitbucket.org/fflo/semix/pkg/memory"
+	"bitbucket.org/fflo/semix/pkg/semix"
+)
+
+// Model is a naive Bayes model that estimates the probability
+// of a sense given the concepts in its context.
+// The context of a sense consists of the concepts of its
+// memory and all concepts they reference.
+type Model struct {
+	MemorySize int
+	Senses     map[string]float64
+	Features   map[string]map[string]float64
+	Totals     map[string]float64
+	Vocabulary map[string]bool
+	N          float64
+}
+
+// NewModel creates a new empty model that is trained
+// with memories of the given size.
+func NewModel(n int) *Model {
+	return &Model{
+		MemorySize: n,
+		Senses:     make(map[string]float64),
+		Features:   make(map[string]map[string]float64),
+		Totals:     make(map[string]float64),
+		Vocabulary: make(map[string]bool),
+	}
+}
+
+// ReadModel reads a gob encoded model.
+func ReadModel(r io.Reader) (*Model, error) {
+	m := new(Model)
+	if err := gob.NewDecoder(r).Decode(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Write writes the gob encoded model.
+func (m *Model) Write(w io.Writer) error {
+	return gob.NewEncoder(w).Encode(m)
+}
+
+// Add adds one observation of the given sense in the
+// context of the given memory to the model.
+func (m *Model) Add(sense *semix.Concept, mem *memory.Memory) {
+	url := sense.URL()
+	m.N++
+	m.Senses[url]++
+	if m.Features[url] == nil {
+		m.Features[url] = make(map[string]float64)
+	}
+	for f := range mem.ElementsS() {
+		m.Features[url][f]++
+		m.Totals[url]++
+		m.Vocabulary[f] = true
+	}
+}
+
+// Train trains the model with the tokens of the given stream.
+// The gold function must return the URL of the correct sense
+// of an ambiguous token or the empty string if the sense
+// of the token is not known. Unambiguous concepts and the correct
+// senses of ambiguous concepts are pushed into the memory.
+func (m *Model) Train(ctx context.Context, s semix.Stream, gold func(semix.Token) string) error {
+	mems := make(map[string]*memory.Memory)
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case t, ok := <-s:
+			if !ok {
+				return nil
+			}
+			if t.Err != nil {
+				return t.Err
+			}
+			if t.Token.Concept == nil {
+				continue
+			}
+			mem := mems[t.Token.Path]
+			if mem == nil {
+				mem = memory.New(m.MemorySize)
+				mems[t.Token.Path] = mem
+			}
+			if !t.Token.Concept.Ambig() {
+				mem.Push(t.Token.Concept)
+				continue
+			}
+			url := gold(t.Token)
+			for _, sense := range referencedConcepts(t.Token.Concept) {
+				if sense.URL() == url {
+					m.Add(sense, mem)
+					mem.Push(sense)
+					break
+				}
+			}
+		}
+	}
+}
+
+// score returns the logarithmic probability of the sense
+// in the context of the given features. Add one smoothing
+// is used for unseen senses and features. The number of
+// candidate senses is given by k.
+func (m *Model) score(sense string, k int, features map[string]*semix.Concept) float64 {
+	score := math.Log((m.Senses[sense] + 1) / (m.N + float64(k)))
+	v := float64(len(m.Vocabulary) + 1)
+	for f := range features {
+		score += math.Log((m.Features[sense][f] + 1) / (m.Totals[sense] + v))
+	}
+	return score
+}
+
+// Bayes resolves ambiguities using a trained naive Bayes model.
+type Bayes struct {
+	Model *Model
+}
+
+// Resolve returns the most probable sense of the ambiguous concept.
+func (b Bayes) Resolve(c *semix.Concept, mem *memory.Memory) *semix.Concept {
+	return best(b.Scores(c, mem))
+}
+
+// Scores returns the posterior probabilities of the possible senses.
+func (b Bayes) Scores(c *semix.Concept, mem *memory.Memory) []Candidate {
+	features := mem.ElementsS()
+	k := len(referencedConcepts(c))
+	cs := candidates(c, func(c *semix.Concept) float64 {
+		return b.Model.score(c.URL(), k, features)
+	})
+	max := -math.MaxFloat64
+	for _, c := range cs {
+		max = math.Max(max, c.Score)
+	}
+	var sum float64
+	for i := range cs {
+		cs[i].Score = math.Exp(cs[i].Score - max)
+		sum += cs[i].Score
+	}
+	for i := range cs {
+		cs[i].Score /= sum
+	}
+	return cs
+}
@@ -0,0 +1,187 @@
+package resolve
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"bitbucket.org/fflo/semix/pkg/semix"
+)
+
+// Vote defines how the senses of the occurrences of an
+// ambiguous concept in a document are combined.
+type Vote string
+
+// Names of the different votes.
+const (
+	// NoVote resolves every occurrence independently.
+	NoVote Vote = ""
+	// MajorityVote chooses the sense that was chosen most often.
+	MajorityVote Vote = "majority"
+	// ScoreVote chooses the sense with the highest sum of scores.
+	// It should only be used with resolvers that are Scorers.
+	ScoreVote Vote = "score"
+)
+
+// NewVote creates a new vote and checks its name.
+// The name "none" is an alias for NoVote.
+func NewVote(name string) (Vote, error) {
+	v := Vote(strings.ToLower(name))
+	if v == "none" {
+		v = NoVote
+	}
+	switch v {
+	case NoVote, MajorityVote, ScoreVote:
+		return v, nil
+	}
+	return NoVote, fmt.Errorf("invalid vote: %s", name)
+}
+
+// WithOneSensePerDocument resolves the ambiguities of a document in two
+// passes. In the first pass, all occurrences are resolved independently.
+// In the second pass, the sense with the best vote is assigned to all
+// occurrences of an ambiguous concept in the document. If no sense has
+// a unique best vote, the results of the first pass are kept.
+// The tokens of a document must be consecutive on the stream.
+//
+// The tokens of a document are buffered for the vote. To bound the
+// memory, long documents can be split into windows of consecutive
+// tokens (see WithVoteWindow) that are resolved independently.
+func WithOneSensePerDocument(v Vote) Option {
+	return func(c *config) {
+		c.vote = v
+	}
+}
+
+// WithVoteWindow sets the maximal number of tokens of a document
+// that are buffered and resolved together with one sense per document.
+// If n <= 0 (the default), all tokens of a document are buffered.
+func WithVoteWindow(n int) Option {
+	return func(c *config) {
+		c.window = n
+	}
+}
+
+// resolveDocuments buffers the tokens of each document (or each
+// window of a document) and resolves them using resolveDocument.
+func resolveDocuments(ctx context.Context, n int, r Interface, s semix.Stream, cfg config) semix.Stream {
+	rstream := make(chan semix.StreamToken)
+	go func() {
+		defer close(rstream)
+		var doc []semix.StreamToken
+		flush := func() bool {
+			for _, t := range resolveDocument(ctx, n, r, doc, cfg) {
+				select {
+				case <-ctx.Done():
+					return false
+				case rstream <- t:
+				}
+			}
+			doc = doc[:0]
+			return true
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case t, ok := <-s:
+				if !ok {
+					flush()
+					return
+				}
+				if t.Err == nil && len(doc) > 0 && doc[0].Token.Path != t.Token.Path {
+					if !flush() {
+						return
+					}
+				}
+				doc = append(doc, t)
+				if cfg.window > 0 && len(doc) >= cfg.window {
+					if !flush() {
+						return
+					}
+				}
+			}
+		}
+	}()
+	return rstream
+}
+
+// resolveDocument resolves the tokens of one document.
+func resolveDocument(ctx context.Context, n int, r Interface, doc []semix.StreamToken, cfg config) []semix.StreamToken {
+	in := make(chan semix.StreamToken, len(doc))
+	for _, t := range doc {
+		in <- t
+	}
+	close(in)
+	vote := cfg.vote
+	cfg.vote = NoVote
+	var res []semix.StreamToken
+	for t := range resolveStream(ctx, n, r, in, cfg) {
+		res = append(res, t)
+	}
+	if len(res) != len(doc) { // canceled
+		return res
+	}
+	senses := votes(doc, res, vote)
+	for i := range doc {
+		if !ambiguous(doc[i]) {
+			continue
+		}
+		b := senses[doc[i].Token.Concept.URL()]
+		if b.sense == nil {
+			continue
+		}
+		res[i].Token = doc[i].Token
+		res[i].Token.Concept = b.sense
+		res[i].Token.Resolver = cfg.name
+		res[i].Token.Score = b.score
+		res[i].Token.Candidates = len(referencedConcepts(doc[i].Token.Concept))
+	}
+	return res
+}
+
+type ballot struct {
+	sense *semix.Concept
+	score float64
+}
+
+// votes returns the best sense for each ambiguous concept of the
+// document. The score of the best sense is its share of all votes.
+func votes(doc, res []semix.StreamToken, v Vote) map[string]ballot {
+	counts := make(map[string]map[*semix.Concept]float64)
+	for i := range doc {
+		if !ambiguous(doc[i]) || doc[i].Token.Nested || res[i].Token.Concept.Ambig() {
+			continue
+		}
+		url := doc[i].Token.Concept.URL()
+		if counts[url] == nil {
+			counts[url] = make(map[*semix.Concept]float64)
+		}
+		score := 1.0
+		if v == ScoreVote {
+			score = res[i].Token.Score
+		}
+		counts[url][res[i].Token.Concept] += score
+	}
+	senses := make(map[string]ballot, len(counts))
+	for url, cs := range counts {
+		var total float64
+		var b ballot
+		var ties int
+		for c, score := range cs {
+			total += score
+			switch {
+			case b.sense == nil || score > b.score:
+				b, ties = ballot{sense: c, score: score}, 0
+			case score == b.score:
+				ties++
+			}
+		}
+		if ties > 0 || total <= 0 {
+			continue
+		}
+		b.score /= total
+		senses[url] = b
+	}
+	return senses
+}
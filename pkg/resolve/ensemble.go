@@ -0,0 +1,73 @@
+package resolve
+
+import (
+	"bitbucket.org/fflo/semix/pkg/memory"
+	"bitbucket.org/fflo/semix/pkg/semix"
+)
+
+// Member is a weighted member of an ensemble.
+type Member struct {
+	Scorer Scorer
+	Weight float64
+}
+
+// Ensemble resolves ambiguities using weighted voting of its members.
+// The scores of each member are normalized to sum up to 1 and
+// combined using the members' weights. The combined scores are
+// normalized by the sum of the weights of the members that do not
+// abstain. If the best combined score
+// is below the threshold, the ensemble abstains.
+type Ensemble struct {
+	Members   []Member
+	Threshold float64
+}
+
+// Resolve returns the sense with the best combined score or nil
+// if the best combined score is ambiguous or below the threshold.
+func (e Ensemble) Resolve(c *semix.Concept, mem *memory.Memory) *semix.Concept {
+	return e.Choose(e.Scores(c, mem))
+}
+
+// Choose returns the sense with the best combined score or nil
+// if the best combined score is ambiguous or below the threshold.
+func (e Ensemble) Choose(cs []Candidate) *semix.Concept {
+	r := best(cs)
+	if r == nil {
+		return nil
+	}
+	for _, c := range cs {
+		if c.Concept == r && c.Score < e.Threshold {
+			return nil
+		}
+	}
+	return r
+}
+
+// Scores returns the combined scores of the possible senses.
+// Members that score all senses with 0 abstain and do not
+// contribute to the combined scores.
+func (e Ensemble) Scores(c *semix.Concept, mem *memory.Memory) []Candidate {
+	cs := candidates(c, func(*semix.Concept) float64 { return 0 })
+	var total float64
+	for _, m := range e.Members {
+		scores := m.Scorer.Scores(c, mem)
+		var sum float64
+		for _, s := range scores {
+			sum += s.Score
+		}
+		if sum <= 0 {
+			continue
+		}
+		total += m.Weight
+		for i := range scores {
+			cs[i].Score += m.Weight * scores[i].Score / sum
+		}
+	}
+	if total <= 0 {
+		return cs
+	}
+	for i := range cs {
+		cs[i].Score /= total
+	}
+	return cs
+}
@@ -45,13 +45,26 @@ func referencedConcepts(c *semix.Concept) []*semix.Concept {
 	return cs
 }
 
-// resolve calculates a score for each referenced concept and returns the
-// concept with the maximal score.  The given concept must be ambigiuous.
-func resolve(c *semix.Concept, f func(*semix.Concept) float64) *semix.Concept {
+// candidates calculates a score for each referenced concept.
+// The given concept must be ambigiuous.
+func candidates(c *semix.Concept, f func(*semix.Concept) float64) []Candidate {
 	cs := referencedConcepts(c)
+	res := make([]Candidate, len(cs))
+	for i := range cs {
+		res[i] = Candidate{Concept: cs[i], Score: f(cs[i])}
+	}
+	return res
+}
+
+// best returns the candidate with the maximal score.
+// If more than one candidate with the maximal score
+// can be found, nil is returned.
+func best(cs []Candidate) *semix.Concept {
+	concepts := make([]*semix.Concept, len(cs))
 	scores := make([]float64, len(cs))
 	for i := range cs {
-		scores[i] = f(cs[i])
+		concepts[i] = cs[i].Concept
+		scores[i] = cs[i].Score
 	}
-	return maxConcept(cs, scores)
+	return maxConcept(concepts, scores)
 }
@@ -0,0 +1,246 @@
+package resolve
+
+import (
+	"container/list"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"bitbucket.org/fflo/semix/pkg/memory"
+	"bitbucket.org/fflo/semix/pkg/semix"
+)
+
+// Default values for the PageRank resolver.
+const (
+	DefaultIterations = 20
+	DefaultDamping    = 0.85
+	DefaultCacheSize  = 128
+)
+
+// PageRank disambiguates concepts using a personalized PageRank over the
+// graph. The random walk restarts at the concepts in the memory, weighted
+// with their weights in the memory, and follows the edges of the graph
+// in both directions. The possible sense with the highest rank is chosen.
+// The ranks are cached for each context. It is safe to use a PageRank
+// resolver concurrently.
+type PageRank struct {
+	iterations int
+	damping    float64
+	predicates map[string]bool
+	in         map[*semix.Concept][]*semix.Concept
+	cache      *rankCache
+}
+
+// PageRankOption defines an option for the PageRank resolver.
+type PageRankOption func(*PageRank)
+
+// WithIterations sets the number of iterations of the random walk.
+func WithIterations(n int) PageRankOption {
+	return func(p *PageRank) {
+		p.iterations = n
+	}
+}
+
+// WithDamping sets the probability to follow an edge
+// instead of restarting the random walk.
+func WithDamping(d float64) PageRankOption {
+	return func(p *PageRank) {
+		p.damping = d
+	}
+}
+
+// WithPredicates sets the URLs of the predicates whose edges are
+// followed by the random walk. If no predicates are given, all
+// edges are followed.
+func WithPredicates(urls ...string) PageRankOption {
+	return func(p *PageRank) {
+		if len(urls) == 0 {
+			p.predicates = nil
+			return
+		}
+		p.predicates = make(map[string]bool, len(urls))
+		for _, url := range urls {
+			p.predicates[url] = true
+		}
+	}
+}
+
+// WithCacheSize sets the number of contexts whose ranks are cached.
+func WithCacheSize(n int) PageRankOption {
+	return func(p *PageRank) {
+		p.cache = newRankCache(n)
+	}
+}
+
+// NewPageRank creates a new PageRank resolver for the given graph.
+func NewPageRank(g *semix.Graph, opts ...PageRankOption) *PageRank {
+	p := &PageRank{
+		iterations: DefaultIterations,
+		damping:    DefaultDamping,
+		in:         make(map[*semix.Concept][]*semix.Concept),
+		cache:      newRankCache(DefaultCacheSize),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	for i := 0; g != nil && i < g.ConceptsLen(); i++ {
+		c := g.ConceptAt(i)
+		c.EachEdge(func(e semix.Edge) {
+			if p.follow(c, e) {
+				p.in[e.O] = append(p.in[e.O], c)
+			}
+		})
+	}
+	return p
+}
+
+// Resolve returns the possible sense with the highest rank.
+func (p *PageRank) Resolve(c *semix.Concept, mem *memory.Memory) *semix.Concept {
+	return best(p.Scores(c, mem))
+}
+
+// Scores returns the ranks of the possible senses.
+func (p *PageRank) Scores(c *semix.Concept, mem *memory.Memory) []Candidate {
+	ranks := p.ranks(mem)
+	return candidates(c, func(c *semix.Concept) float64 {
+		return ranks[c.URL()]
+	})
+}
+
+// ranks returns the ranks of the concepts for the given memory.
+func (p *PageRank) ranks(mem *memory.Memory) map[string]float64 {
+	restart, key := restartVector(mem)
+	if ranks, ok := p.cache.get(key); ok {
+		return ranks
+	}
+	ranks := make(map[string]float64)
+	for c, r := range p.walk(restart) {
+		ranks[c.URL()] += r
+	}
+	p.cache.put(key, ranks)
+	return ranks
+}
+
+// walk runs the random walk with the given restart vector.
+// The mass of concepts without any edges is redistributed
+// using the restart vector.
+func (p *PageRank) walk(restart map[*semix.Concept]float64) map[*semix.Concept]float64 {
+	neighbours := make(map[*semix.Concept][]*semix.Concept)
+	ranks := restart
+	for i := 0; i < p.iterations; i++ {
+		next := make(map[*semix.Concept]float64, len(ranks))
+		for c, r := range restart {
+			next[c] += (1 - p.damping) * r
+		}
+		for c, r := range ranks {
+			ns, ok := neighbours[c]
+			if !ok {
+				ns = p.neighbours(c)
+				neighbours[c] = ns
+			}
+			if len(ns) == 0 {
+				for o, s := range restart {
+					next[o] += p.damping * r * s
+				}
+				continue
+			}
+			share := p.damping * r / float64(len(ns))
+			for _, n := range ns {
+				next[n] += share
+			}
+		}
+		ranks = next
+	}
+	return ranks
+}
+
+func (p *PageRank) neighbours(c *semix.Concept) []*semix.Concept {
+	var ns []*semix.Concept
+	c.EachEdge(func(e semix.Edge) {
+		if p.follow(c, e) {
+			ns = append(ns, e.O)
+		}
+	})
+	return append(ns, p.in[c]...)
+}
+
+func (p *PageRank) follow(c *semix.Concept, e semix.Edge) bool {
+	if c.Ambig() || e.O.Ambig() {
+		return false
+	}
+	return p.predicates == nil || p.predicates[e.P.URL()]
+}
+
+// restartVector returns the normalized weights of the concepts
+// in the memory and a key that identifies the context.
+func restartVector(mem *memory.Memory) (map[*semix.Concept]float64, string) {
+	restart := make(map[*semix.Concept]float64)
+	var sum float64
+	mem.EachW(func(c *semix.Concept, w float64) {
+		if c.Ambig() || w <= 0 {
+			return
+		}
+		restart[c] += w
+		sum += w
+	})
+	keys := make([]string, 0, len(restart))
+	for c := range restart {
+		restart[c] /= sum
+		keys = append(keys, fmt.Sprintf("%s %g", c.URL(), restart[c]))
+	}
+	sort.Strings(keys)
+	return restart, strings.Join(keys, "\n")
+}
+
+// rankCache is a simple LRU cache for the ranks of contexts.
+type rankCache struct {
+	mutex sync.Mutex
+	n     int
+	lru   *list.List
+	elems map[string]*list.Element
+}
+
+type rankCacheEntry struct {
+	key   string
+	ranks map[string]float64
+}
+
+func newRankCache(n int) *rankCache {
+	return &rankCache{n: n, lru: list.New(), elems: make(map[string]*list.Element)}
+}
+
+func (c *rankCache) get(key string) (map[string]float64, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	e, ok := c.elems[key]
+	if !ok {
+		return nil, false
+	}
+	c.lru.MoveToFront(e)
+	return e.Value.(rankCacheEntry).ranks, true
+}
+
+func (c *rankCache) put(key string, ranks map[string]float64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.n <= 0 {
+		return
+	}
+	if e, ok := c.elems[key]; ok {
+		c.lru.MoveToFront(e)
+		return
+	}
+	c.elems[key] = c.lru.PushFront(rankCacheEntry{key: key, ranks: ranks})
+	for c.lru.Len() > c.n {
+		e := c.lru.Back()
+		c.lru.Remove(e)
+		delete(c.elems, e.Value.(rankCacheEntry).key)
+	}
+}
+
+func (c *rankCache) len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.lru.Len()
+}
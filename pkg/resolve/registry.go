@@ -0,0 +1,259 @@
+package resolve
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"bitbucket.org/fflo/semix/pkg/rule"
+	"bitbucket.org/fflo/semix/pkg/semix"
+)
+
+// Params holds the key value parameters of a resolver.
+type Params map[string]string
+
+// Float returns the float parameter for the given key
+// or def if the parameter is not set.
+func (p Params) Float(key string, def float64) (float64, error) {
+	str, ok := p[key]
+	if !ok {
+		return def, nil
+	}
+	f, err := strconv.ParseFloat(str, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid parameter %s: %s", key, str)
+	}
+	return f, nil
+}
+
+// Int returns the integer parameter for the given key
+// or def if the parameter is not set.
+func (p Params) Int(key string, def int) (int, error) {
+	str, ok := p[key]
+	if !ok {
+		return def, nil
+	}
+	i, err := strconv.Atoi(str)
+	if err != nil {
+		return 0, fmt.Errorf("invalid parameter %s: %s", key, str)
+	}
+	return i, nil
+}
+
+// Strings returns the list parameter for the given key.
+// The elements of lists are separated by |.
+func (p Params) Strings(key string) []string {
+	var strs []string
+	for _, str := range strings.Split(p[key], "|") {
+		if str = strings.TrimSpace(str); str != "" {
+			strs = append(strs, str)
+		}
+	}
+	return strs
+}
+
+// Resources holds the resources that are available to the factories.
+// If set, the Cache is used to share resolvers between the streams
+// that use the same resources.
+type Resources struct {
+	Graph *semix.Graph
+	Rules rule.Map
+	Model *Model
+	Cache *Cache
+}
+
+// maxCacheSize is the maximal number of resolvers in a Cache.
+const maxCacheSize = 64
+
+// Cache shares resolvers that are expensive to create.
+// It is safe for concurrent use. The cached resolvers must
+// be safe for concurrent use as well. If the cache is full,
+// the least recently used resolver is removed.
+type Cache struct {
+	mutex   sync.Mutex
+	entries map[string]*cacheEntry
+	clock   uint64
+}
+
+// cacheEntry holds a cached resolver. The resolver and the error
+// are set before done is closed.
+type cacheEntry struct {
+	done chan struct{}
+	r    Interface
+	err  error
+	used uint64
+}
+
+// NewCache creates a new empty cache.
+func NewCache() *Cache {
+	return &Cache{entries: make(map[string]*cacheEntry)}
+}
+
+// Get returns the cached resolver for the given key. If no resolver
+// is cached for the key, the resolver is created with f and cached.
+// Concurrent calls for the same key wait for the first call to create
+// the resolver. Resolvers that cannot be created are not cached.
+// If the cache is nil, the resolver is created without caching.
+func (c *Cache) Get(key string, f func() (Interface, error)) (Interface, error) {
+	if c == nil {
+		return f()
+	}
+	c.mutex.Lock()
+	e, ok := c.entries[key]
+	if !ok {
+		c.evict()
+		e = &cacheEntry{done: make(chan struct{})}
+		c.entries[key] = e
+	}
+	c.clock++
+	e.used = c.clock
+	c.mutex.Unlock()
+	if ok {
+		<-e.done
+		return e.r, e.err
+	}
+	func() {
+		defer close(e.done)
+		e.r, e.err = f()
+	}()
+	if e.err != nil {
+		c.mutex.Lock()
+		if c.entries[key] == e {
+			delete(c.entries, key)
+		}
+		c.mutex.Unlock()
+	}
+	return e.r, e.err
+}
+
+// evict removes the least recently used entry if the cache is full.
+func (c *Cache) evict() {
+	if len(c.entries) < maxCacheSize {
+		return
+	}
+	var lru string
+	used := c.clock + 1
+	for key, e := range c.entries {
+		if e.used < used {
+			lru, used = key, e.used
+		}
+	}
+	delete(c.entries, lru)
+}
+
+// Factory creates a new resolver with the given parameters and resources.
+type Factory func(Params, Resources) (Interface, error)
+
+var registry = struct {
+	mutex     sync.RWMutex
+	factories map[string]Factory
+}{factories: make(map[string]Factory)}
+
+// Register registers a resolver factory under the given name.
+// Names are case insensitive. Register panics if
+// a factory with the same name is already registered.
+func Register(name string, f Factory) {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	name = strings.ToLower(name)
+	if _, ok := registry.factories[name]; ok {
+		panic("resolve: Register called twice for resolver " + name)
+	}
+	registry.factories[name] = f
+}
+
+// Unregister removes the factory that is registered under the given name.
+// It is intended for tests that register their own resolvers.
+func Unregister(name string) {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	delete(registry.factories, strings.ToLower(name))
+}
+
+// Registered returns true if a factory is registered under the given name.
+func Registered(name string) bool {
+	registry.mutex.RLock()
+	defer registry.mutex.RUnlock()
+	_, ok := registry.factories[strings.ToLower(name)]
+	return ok
+}
+
+// Names returns the sorted names of all registered resolvers.
+func Names() []string {
+	registry.mutex.RLock()
+	defer registry.mutex.RUnlock()
+	names := make([]string, 0, len(registry.factories))
+	for name := range registry.factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New creates a new resolver using the factory
+// that is registered under the given name.
+func New(name string, ps Params, rs Resources) (Interface, error) {
+	registry.mutex.RLock()
+	f, ok := registry.factories[strings.ToLower(name)]
+	registry.mutex.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("invalid resolver name: %s", name)
+	}
+	r, err := f(ps, rs)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create resolver %s: %v", name, err)
+	}
+	return r, nil
+}
+
+func init() {
+	Register("simple", func(Params, Resources) (Interface, error) {
+		return Simple{}, nil
+	})
+	Register("thematic", func(ps Params, _ Resources) (Interface, error) {
+		t, err := ps.Float("threshold", 0)
+		if err != nil {
+			return nil, err
+		}
+		return Automatic{Threshold: t}, nil
+	})
+	Register("ruled", func(_ Params, rs Resources) (Interface, error) {
+		return Ruled{Rules: rs.Rules, Graph: rs.Graph}, nil
+	})
+	Register("bayes", func(_ Params, rs Resources) (Interface, error) {
+		if rs.Model == nil {
+			return nil, fmt.Errorf("no model")
+		}
+		return Bayes{Model: rs.Model}, nil
+	})
+	Register("pagerank", newPageRankFromParams)
+}
+
+func newPageRankFromParams(ps Params, rs Resources) (Interface, error) {
+	n, err := ps.Int("iterations", DefaultIterations)
+	if err != nil {
+		return nil, err
+	}
+	d, err := ps.Float("damping", DefaultDamping)
+	if err != nil {
+		return nil, err
+	}
+	c, err := ps.Int("cache", DefaultCacheSize)
+	if err != nil {
+		return nil, err
+	}
+	predicates := ps.Strings("predicates")
+	sorted := append([]string(nil), predicates...)
+	sort.Strings(sorted)
+	key := fmt.Sprintf("pagerank %d %g %d %s", n, d, c, strings.Join(sorted, "|"))
+	return rs.Cache.Get(key, func() (Interface, error) {
+		return NewPageRank(rs.Graph,
+			WithIterations(n),
+			WithDamping(d),
+			WithCacheSize(c),
+			WithPredicates(predicates...),
+		), nil
+	})
+}
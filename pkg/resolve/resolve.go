@@ -15,39 +15,282 @@ type Interface interface {
 	Resolve(*semix.Concept, *memory.Memory) *semix.Concept
 }
 
+// Candidate is a possible sense of an ambiguous concept with its score.
+type Candidate struct {
+	Concept *semix.Concept
+	Score   float64
+}
+
+// Scorer is implemented by resolvers that can expose the scores of
+// all possible senses of an ambiguous concept. Higher scores denote
+// better senses. Scores are never negative. The streams of Resolve
+// do not call the Resolve method of a Scorer, but its Scores method,
+// and choose the sense with the unique highest score. Scorers that
+// choose their senses differently must implement Chooser.
+type Scorer interface {
+	Interface
+	Scores(*semix.Concept, *memory.Memory) []Candidate
+}
+
+// Chooser is implemented by Scorers that choose the resolved sense
+// differently from the sense with the unique highest score. Choose
+// returns the sense that Resolve would return for the given scores.
+type Chooser interface {
+	Scorer
+	Choose([]Candidate) *semix.Concept
+}
+
+// choose returns the chosen sense of the Scorer for the given scores.
+func choose(s Scorer, cs []Candidate) *semix.Concept {
+	if c, ok := s.(Chooser); ok {
+		return c.Choose(cs)
+	}
+	return best(cs)
+}
+
+type config struct {
+	decay     memory.Decay
+	lookahead int
+	name      string
+	vote      Vote
+	window    int
+}
+
+// Option defines an option for Resolve.
+type Option func(*config)
+
+// WithDecay sets the decay of the memory that is used to resolve
+// the ambiguities. If the decay uses offsets, the distances of the
+// concepts in the memory are measured in characters; otherwise
+// they are measured in resolved tokens.
+func WithDecay(d memory.Decay) Option {
+	return func(c *config) {
+		c.decay = d
+	}
+}
+
+// WithLookahead sets the number of following matched tokens that are
+// buffered before an ambiguous token is resolved. The unambiguous
+// concepts of the buffered tokens are added to the memory as
+// right context of the ambiguous token. The order of the tokens
+// on the stream is preserved. An ambiguous token is resolved with
+// less right context at the end of its document or if
+// MaxLookaheadTokens tokens are buffered.
+func WithLookahead(k int) Option {
+	return func(c *config) {
+		c.lookahead = k
+	}
+}
+
+// WithName sets the name of the resolver that is recorded
+// in the resolved tokens.
+func WithName(name string) Option {
+	return func(c *config) {
+		c.name = name
+	}
+}
+
 // Resolve resolves ambiguities using the given Interface.
-func Resolve(ctx context.Context, n int, r Interface, s semix.Stream) semix.Stream {
+// The name of the resolver, the score of the chosen concept and
+// the number of candidates are recorded in the resolved tokens.
+// If the resolver is not a Scorer, the score is 0.
+// Nested matches are resolved as well, but they are never
+// added to the context of the other matches.
+func Resolve(ctx context.Context, n int, r Interface, s semix.Stream, opts ...Option) semix.Stream {
+	var cfg config
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.vote != NoVote {
+		return resolveDocuments(ctx, n, r, s, cfg)
+	}
+	return resolveStream(ctx, n, r, s, cfg)
+}
+
+func resolveStream(ctx context.Context, n int, r Interface, s semix.Stream, cfg config) semix.Stream {
 	rstream := make(chan semix.StreamToken)
 	go func() {
 		defer close(rstream)
-		mem := make(map[string]*memory.Memory)
+		q := queue{cfg: cfg, n: n, r: r, mem: make(map[string]*memory.Memory)}
 		for {
 			select {
 			case <-ctx.Done():
 				return
 			case t, ok := <-s:
 				if !ok {
+					for len(q.tokens) > 0 {
+						if !put(ctx, rstream, q.next()) {
+							return
+						}
+					}
 					return
 				}
-				if t.Err == nil && mem[t.Token.Path] == nil {
-					mem[t.Token.Path] = memory.New(n)
-				}
-				if t.Err == nil && t.Token.Concept != nil && t.Token.Concept.Ambig() {
-					t.Token = doResolve(t.Token, r, mem[t.Token.Path])
+				// the document of the buffered tokens has ended
+				for len(q.tokens) > 0 && t.Err == nil && q.tokens[0].Token.Path != t.Token.Path {
+					if !put(ctx, rstream, q.next()) {
+						return
+					}
 				}
-				if t.Err == nil && t.Token.Concept != nil && !t.Token.Concept.Ambig() {
-					mem[t.Token.Path].Push(t.Token.Concept)
+				q.push(t)
+				for q.ready() {
+					if !put(ctx, rstream, q.next()) {
+						return
+					}
 				}
-				rstream <- t
 			}
 		}
 	}()
 	return rstream
 }
 
-func doResolve(t semix.Token, r Interface, mem *memory.Memory) semix.Token {
-	if c := r.Resolve(t.Concept, mem); c != nil {
-		t.Concept = c
+// MaxLookaheadTokens is the maximal number of tokens
+// that are buffered for the lookahead (see WithLookahead).
+const MaxLookaheadTokens = 4096
+
+// queue buffers the tokens of the stream until they can be resolved.
+// matched counts the matched tokens in the queue that are used as
+// right context.
+type queue struct {
+	cfg     config
+	n       int
+	r       Interface
+	mem     map[string]*memory.Memory
+	tokens  []semix.StreamToken
+	matched int
+}
+
+// push appends the token to the queue.
+func (q *queue) push(t semix.StreamToken) {
+	q.tokens = append(q.tokens, t)
+	if matched(t) {
+		q.matched++
+	}
+}
+
+// ready returns true if the first token in the queue can be resolved.
+// This is the case if it is not ambiguous, if it is followed by
+// enough matched tokens or if the queue is full.
+func (q *queue) ready() bool {
+	if len(q.tokens) == 0 {
+		return false
+	}
+	if !ambiguous(q.tokens[0]) || len(q.tokens) >= MaxLookaheadTokens {
+		return true
+	}
+	k := q.matched
+	if matched(q.tokens[0]) {
+		k--
+	}
+	return k >= q.cfg.lookahead
+}
+
+// next removes the first token from the queue, resolves it
+// and updates the memory.
+func (q *queue) next() semix.StreamToken {
+	t := q.tokens[0]
+	q.tokens[0] = semix.StreamToken{}
+	q.tokens = q.tokens[1:]
+	if matched(t) {
+		q.matched--
+	}
+	if t.Err != nil {
+		return t
+	}
+	mem := q.mem[t.Token.Path]
+	if mem == nil {
+		mem = memory.NewWeighted(q.n, q.cfg.decay)
+		q.mem[t.Token.Path] = mem
+	}
+	if q.cfg.decay.Offsets && !t.Token.Nested {
+		mem.At(t.Token.Begin)
+	}
+	if ambiguous(t) {
+		t.Token = doResolve(t.Token, q.r, q.cfg.name, q.context(t.Token, mem))
+	}
+	if t.Token.Concept != nil && !t.Token.Concept.Ambig() && !t.Token.Nested {
+		push(mem, t.Token, q.cfg.decay.Offsets)
+	}
+	return t
+}
+
+// context returns the memory with the left context of the given token.
+// In lookahead mode, the unambiguous concepts of the following
+// tokens are added as right context.
+func (q *queue) context(t semix.Token, mem *memory.Memory) *memory.Memory {
+	if q.cfg.lookahead <= 0 {
+		return mem
+	}
+	ctx := mem.Extend(q.cfg.lookahead)
+	var j int
+	for _, r := range q.tokens {
+		if j == q.cfg.lookahead {
+			break
+		}
+		if !matched(r) {
+			continue
+		}
+		j++
+		if r.Token.Path != t.Path || r.Token.Concept.Ambig() {
+			continue
+		}
+		if q.cfg.decay.Offsets {
+			ctx.PushAt(r.Token.Concept, r.Token.Begin)
+		} else {
+			ctx.PushAt(r.Token.Concept, ctx.Pos()+j)
+		}
+	}
+	return ctx
+}
+
+// matched returns true if the token is a matched token
+// that is not nested in another match.
+func matched(t semix.StreamToken) bool {
+	return t.Err == nil && t.Token.Concept != nil && !t.Token.Nested
+}
+
+func put(ctx context.Context, s chan semix.StreamToken, t semix.StreamToken) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case s <- t:
+		return true
+	}
+}
+
+func ambiguous(t semix.StreamToken) bool {
+	return t.Err == nil && t.Token.Concept != nil && t.Token.Concept.Ambig()
+}
+
+func push(mem *memory.Memory, t semix.Token, offsets bool) {
+	if offsets {
+		mem.PushAt(t.Concept, t.Begin)
+		return
+	}
+	mem.Push(t.Concept)
+}
+
+func doResolve(t semix.Token, r Interface, name string, mem *memory.Memory) semix.Token {
+	var c *semix.Concept
+	var score float64
+	if s, ok := r.(Scorer); ok {
+		cs := s.Scores(t.Concept, mem)
+		if c = choose(s, cs); c != nil {
+			for _, cand := range cs {
+				if cand.Concept == c {
+					score = cand.Score
+					break
+				}
+			}
+		}
+	} else {
+		c = r.Resolve(t.Concept, mem)
+	}
+	if c == nil {
+		return t
 	}
+	t.Resolver = name
+	t.Candidates = len(referencedConcepts(t.Concept))
+	t.Score = score
+	t.Concept = c
 	return t
 }
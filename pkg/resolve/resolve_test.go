@@ -1,8 +1,14 @@
 package resolve
 
 import (
+	"bytes"
 	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"bitbucket.org/fflo/semix/pkg/memory"
 	"bitbucket.org/fflo/semix/pkg/rule"
@@ -53,6 +59,26 @@ func TestAutomatic(t *testing.T) {
 	checkResolve(t, automatic.Resolve(ambig, mem), b)
 }
 
+func TestWeightedAutomatic(t *testing.T) {
+	split := semix.NewConcept(semix.SplitURL)
+	br := semix.NewConcept("broader")
+	p := semix.NewConcept("politics")
+	q := semix.NewConcept("quantum-physics")
+	a := semix.NewConcept("A", semix.WithEdges(br, p))
+	b := semix.NewConcept("B", semix.WithEdges(br, q))
+	ambig := semix.NewConcept("A-B", semix.WithEdges(split, a, split, b))
+	automatic := Automatic{0.1}
+	mem := memory.New(3)
+	mem.Push(p)
+	mem.Push(q)
+	checkResolve(t, automatic.Resolve(ambig, mem), nil)
+	mem = memory.NewWeighted(3, memory.Decay{Type: memory.ExponentialDecay, Rate: 1})
+	mem.Push(p)
+	mem.Push(q)
+	// overlap(A) = exp(-2)/2; overlap(B) = exp(-1)/2
+	checkResolve(t, automatic.Resolve(ambig, mem), b)
+}
+
 func TestRuled(t *testing.T) {
 	split := semix.NewConcept(semix.SplitURL)
 	a := semix.NewConcept("A", semix.WithID(1))
@@ -84,6 +110,176 @@ func TestRuled(t *testing.T) {
 	checkResolve(t, ruled.Resolve(ambig, mem), b)
 }
 
+func TestEnsemble(t *testing.T) {
+	split := semix.NewConcept(semix.SplitURL)
+	a := semix.NewConcept("A", semix.WithID(1))
+	b := semix.NewConcept("B", semix.WithID(2))
+	ambig := semix.NewConcept("A-B", semix.WithEdges(split, a, split, b))
+	rules, err := rule.NewMap(map[string]string{"A": `cs("A")>0`, "B": `cs("B")>2`}, func(str string) int {
+		switch str {
+		case "A":
+			return 1
+		case "B":
+			return 2
+		default:
+			return -1
+		}
+	})
+	if err != nil {
+		t.Fatalf("got error: %s", err)
+	}
+	mem := memory.New(3)
+	tests := []struct {
+		simple, ruled, threshold float64
+		want                     *semix.Concept
+	}{
+		// simple: A=1/3, B=2/3; ruled: A=1, B=0
+		{1, 1, 0, a},
+		{1, 0, 0, b},
+		{0, 1, 0, a},
+		{1, 1, 0.6, a},
+		{1, 1, 0.7, nil},
+		{1, 0, 0.7, nil},
+		{0, 0, 0, nil},
+	}
+	mem.Push(a)
+	mem.Push(b)
+	mem.Push(b)
+	for _, tc := range tests {
+		t.Run(fmt.Sprintf("%g/%g/%g", tc.simple, tc.ruled, tc.threshold), func(t *testing.T) {
+			ensemble := Ensemble{
+				Members: []Member{
+					{Scorer: Simple{}, Weight: tc.simple},
+					{Scorer: Ruled{Rules: rules}, Weight: tc.ruled},
+				},
+				Threshold: tc.threshold,
+			}
+			checkResolve(t, ensemble.Resolve(ambig, mem), tc.want)
+		})
+	}
+	t.Run("abstain", func(t *testing.T) {
+		ensemble := Ensemble{Members: []Member{{Scorer: Simple{}, Weight: 1}}}
+		checkResolve(t, ensemble.Resolve(ambig, memory.New(3)), nil)
+	})
+	t.Run("abstaining member", func(t *testing.T) {
+		// simple: A=0, B=1; ruled abstains
+		ensemble := Ensemble{
+			Members: []Member{
+				{Scorer: Simple{}, Weight: 1},
+				{Scorer: Ruled{Rules: rules}, Weight: 1},
+			},
+			Threshold: 0.7,
+		}
+		mem := memory.New(3)
+		mem.Push(b)
+		mem.Push(b)
+		checkResolve(t, ensemble.Resolve(ambig, mem), b)
+	})
+}
+
+func TestPageRank(t *testing.T) {
+	g := semix.NewGraph()
+	a, _, p := g.Add("A", "broader", "politics")
+	b, _, q := g.Add("B", "broader", "physics")
+	x, _, _ := g.Add("X", "about", "politics")
+	y, _, _ := g.Add("Y", "about", "physics")
+	split := semix.NewConcept(semix.SplitURL)
+	ambig := semix.NewConcept("A-B", semix.WithEdges(split, a, split, b))
+	tests := []struct {
+		name string
+		opts []PageRankOption
+		mem  []*semix.Concept
+		want *semix.Concept
+	}{
+		{"empty", nil, nil, nil},
+		{"X", nil, []*semix.Concept{x}, a},
+		{"Y", nil, []*semix.Concept{y}, b},
+		{"X Y", nil, []*semix.Concept{x, y}, nil},
+		{"X Y Y", nil, []*semix.Concept{x, y, y}, b},
+		{"politics", nil, []*semix.Concept{p}, a},
+		{"physics", nil, []*semix.Concept{q}, b},
+		{"X broader", []PageRankOption{WithPredicates("broader")}, []*semix.Concept{x}, nil},
+		{"X about", []PageRankOption{WithPredicates("about")}, []*semix.Concept{x}, nil},
+		{"X about broader", []PageRankOption{WithPredicates("about", "broader")}, []*semix.Concept{x}, a},
+		{"X 1 iteration", []PageRankOption{WithIterations(1)}, []*semix.Concept{x}, nil},
+		{"X 2 iterations", []PageRankOption{WithIterations(2)}, []*semix.Concept{x}, a},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			pr := NewPageRank(g, tc.opts...)
+			mem := memory.New(3)
+			for _, c := range tc.mem {
+				mem.Push(c)
+			}
+			checkResolve(t, pr.Resolve(ambig, mem), tc.want)
+		})
+	}
+}
+
+func TestPageRankCache(t *testing.T) {
+	g := semix.NewGraph()
+	a, _, _ := g.Add("A", "broader", "politics")
+	b, _, _ := g.Add("B", "broader", "physics")
+	x, _, _ := g.Add("X", "about", "politics")
+	y, _, _ := g.Add("Y", "about", "physics")
+	split := semix.NewConcept(semix.SplitURL)
+	ambig := semix.NewConcept("A-B", semix.WithEdges(split, a, split, b))
+	pr := NewPageRank(g, WithCacheSize(2))
+	mem := memory.New(1)
+	want := []int{1, 1, 2, 2, 2}
+	for i, c := range []*semix.Concept{x, x, y, x, a} {
+		mem.Push(c)
+		pr.Resolve(ambig, mem)
+		if got := pr.cache.len(); got != want[i] {
+			t.Fatalf("expected %d cached contexts; got %d", want[i], got)
+		}
+	}
+	mem.Push(x)
+	checkResolve(t, pr.Resolve(ambig, mem), a)
+}
+
+func TestBayes(t *testing.T) {
+	split := semix.NewConcept(semix.SplitURL)
+	p := semix.NewConcept("politics")
+	q := semix.NewConcept("quantum-physics")
+	a := semix.NewConcept("A")
+	b := semix.NewConcept("B")
+	ambig := semix.NewConcept("A-B", semix.WithEdges(split, a, split, b))
+	gold := map[int]string{1: "A", 3: "B", 5: "A"}
+	tokens := make(chan semix.StreamToken)
+	go func() {
+		for i, c := range []*semix.Concept{p, ambig, q, ambig, p, ambig} {
+			tokens <- semix.StreamToken{Token: semix.Token{Concept: c, Path: "test", Begin: i}}
+		}
+		close(tokens)
+	}()
+	model := NewModel(1)
+	if err := model.Train(context.TODO(), tokens, func(t semix.Token) string {
+		return gold[t.Begin]
+	}); err != nil {
+		t.Fatalf("got error: %s", err)
+	}
+	if model.Senses["A"] != 2 || model.Senses["B"] != 1 || model.Features["A"]["politics"] != 2 {
+		t.Fatalf("invalid model: %v", model)
+	}
+	var buf bytes.Buffer
+	if err := model.Write(&buf); err != nil {
+		t.Fatalf("got error: %s", err)
+	}
+	model, err := ReadModel(&buf)
+	if err != nil {
+		t.Fatalf("got error: %s", err)
+	}
+	bayes := Bayes{Model: model}
+	mem := memory.New(1)
+	checkResolve(t, bayes.Resolve(ambig, mem), a)
+	mem.Push(q)
+	checkResolve(t, bayes.Resolve(ambig, mem), b)
+	mem.Push(p)
+	checkResolve(t, bayes.Resolve(ambig, mem), a)
+	checkResolve(t, Bayes{Model: NewModel(1)}.Resolve(ambig, mem), nil)
+}
+
 func TestStream(t *testing.T) {
 	split := semix.NewConcept(semix.SplitURL)
 	a := semix.NewConcept("A")
@@ -115,9 +311,512 @@ func TestStream(t *testing.T) {
 	}
 }
 
+func TestStreamProvenance(t *testing.T) {
+	split := semix.NewConcept(semix.SplitURL)
+	a := semix.NewConcept("A")
+	b := semix.NewConcept("B")
+	ambig := semix.NewConcept("A-B", semix.WithEdges(split, a, split, b))
+	tokens := make(chan semix.StreamToken)
+	go func() {
+		tokens <- semix.StreamToken{Token: semix.Token{Concept: a, Path: "test"}}
+		tokens <- semix.StreamToken{Token: semix.Token{Concept: ambig, Path: "test"}}
+		tokens <- semix.StreamToken{Token: semix.Token{Concept: ambig, Path: "other"}}
+		close(tokens)
+	}()
+	var got []semix.Token
+	for tok := range Resolve(context.TODO(), 3, Simple{}, tokens, WithName("simple")) {
+		if tok.Err != nil {
+			t.Fatalf("go error: %s", tok.Err)
+		}
+		got = append(got, tok.Token)
+	}
+	want := []semix.Token{
+		{Concept: a, Path: "test"},
+		{Concept: a, Path: "test", Resolver: "simple", Score: 1, Candidates: 2},
+		{Concept: ambig, Path: "other"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v; got %v", want, got)
+	}
+}
+
+type countingScorer struct {
+	Simple
+	n *int
+}
+
+func (s countingScorer) Scores(c *semix.Concept, mem *memory.Memory) []Candidate {
+	*s.n++
+	return s.Simple.Scores(c, mem)
+}
+
+func (s countingScorer) Resolve(c *semix.Concept, mem *memory.Memory) *semix.Concept {
+	return best(s.Scores(c, mem))
+}
+
+func TestStreamScoresOnce(t *testing.T) {
+	split := semix.NewConcept(semix.SplitURL)
+	a := semix.NewConcept("A")
+	b := semix.NewConcept("B")
+	ambig := semix.NewConcept("A-B", semix.WithEdges(split, a, split, b))
+	var n1, n2 int
+	ensemble := Ensemble{Members: []Member{
+		{Scorer: countingScorer{n: &n1}, Weight: 1},
+		{Scorer: countingScorer{n: &n2}, Weight: 1},
+	}, Threshold: 0.6}
+	tokens := make(chan semix.StreamToken, 4)
+	tokens <- semix.StreamToken{Token: semix.Token{Concept: a, Path: "test"}}
+	tokens <- semix.StreamToken{Token: semix.Token{Concept: ambig, Path: "test"}}
+	tokens <- semix.StreamToken{Token: semix.Token{Concept: b, Path: "test"}}
+	tokens <- semix.StreamToken{Token: semix.Token{Concept: ambig, Path: "test"}}
+	close(tokens)
+	var got []semix.Token
+	for tok := range Resolve(context.TODO(), 3, ensemble, tokens, WithName("ensemble")) {
+		got = append(got, tok.Token)
+	}
+	if n1 != 2 || n2 != 2 {
+		t.Fatalf("expected 2 calls to Scores; got %d and %d", n1, n2)
+	}
+	want := []semix.Token{
+		{Concept: a, Path: "test"},
+		{Concept: a, Path: "test", Resolver: "ensemble", Score: 1, Candidates: 2},
+		{Concept: b, Path: "test"},
+		// A=2/3, B=1/3: the ensemble resolves A
+		{Concept: a, Path: "test", Resolver: "ensemble", Score: 2.0 / 3.0, Candidates: 2},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v; got %v", want, got)
+	}
+}
+
+type neverChooser struct {
+	Simple
+}
+
+func (neverChooser) Choose([]Candidate) *semix.Concept {
+	return nil
+}
+
+func (neverChooser) Resolve(*semix.Concept, *memory.Memory) *semix.Concept {
+	return nil
+}
+
+func TestStreamChooser(t *testing.T) {
+	split := semix.NewConcept(semix.SplitURL)
+	a := semix.NewConcept("A")
+	b := semix.NewConcept("B")
+	ambig := semix.NewConcept("A-B", semix.WithEdges(split, a, split, b))
+	tokens := make(chan semix.StreamToken, 2)
+	tokens <- semix.StreamToken{Token: semix.Token{Concept: a, Path: "test"}}
+	tokens <- semix.StreamToken{Token: semix.Token{Concept: ambig, Path: "test"}}
+	close(tokens)
+	var got []semix.Token
+	for tok := range Resolve(context.TODO(), 3, neverChooser{}, tokens, WithName("never")) {
+		got = append(got, tok.Token)
+	}
+	want := []semix.Token{{Concept: a, Path: "test"}, {Concept: ambig, Path: "test"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v; got %v", want, got)
+	}
+}
+
+func TestStreamWithOneSensePerDocument(t *testing.T) {
+	split := semix.NewConcept(semix.SplitURL)
+	a := semix.NewConcept("A")
+	b := semix.NewConcept("B")
+	ambig := semix.NewConcept("A-B", semix.WithEdges(split, a, split, b))
+	tests := []struct {
+		vote   Vote
+		window int
+		test   []*semix.Concept
+		want   string
+		score  float64
+	}{
+		{MajorityVote, 0, []*semix.Concept{a, ambig, b, b, ambig, ambig}, "A B B B B B", 2.0 / 3.0},
+		{ScoreVote, 0, []*semix.Concept{a, ambig, b, b, ambig, ambig}, "A B B B B B", 0.8},
+		// tie: keep the results of the first pass
+		{MajorityVote, 0, []*semix.Concept{a, ambig, b, b, ambig}, "A A B B B", 0},
+		{MajorityVote, 0, []*semix.Concept{ambig, ambig}, "A-B A-B", 0},
+		// windows a ambig b and b ambig ambig are resolved independently
+		{MajorityVote, 3, []*semix.Concept{a, ambig, b, b, ambig, ambig}, "A A B B B B", 0},
+	}
+	for _, tc := range tests {
+		t.Run(fmt.Sprintf("%s %s", tc.vote, tc.want), func(t *testing.T) {
+			tokens := make(chan semix.StreamToken)
+			go func() {
+				for i, c := range tc.test {
+					tokens <- semix.StreamToken{Token: semix.Token{Concept: c, Path: "test", Begin: i}}
+				}
+				tokens <- semix.StreamToken{Token: semix.Token{Concept: ambig, Path: "other"}}
+				close(tokens)
+			}()
+			var urls []string
+			for tok := range Resolve(context.TODO(), 2, Simple{}, tokens,
+				WithName("simple"), WithOneSensePerDocument(tc.vote), WithVoteWindow(tc.window)) {
+				if tok.Err != nil {
+					t.Fatalf("got error: %s", tok.Err)
+				}
+				if tok.Token.Path != "test" {
+					if tok.Token.Concept != ambig {
+						t.Fatalf("expected %s; got %s", ambig, tok.Token.Concept)
+					}
+					continue
+				}
+				urls = append(urls, tok.Token.Concept.URL())
+				if tc.score > 0 && tc.test[tok.Token.Begin].Ambig() && tok.Token.Score != tc.score {
+					t.Fatalf("expected score %g; got %g", tc.score, tok.Token.Score)
+				}
+			}
+			if got := strings.Join(urls, " "); got != tc.want {
+				t.Fatalf("expected %s; got %s", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestStreamWithDecay(t *testing.T) {
+	split := semix.NewConcept(semix.SplitURL)
+	br := semix.NewConcept("broader")
+	p := semix.NewConcept("politics")
+	q := semix.NewConcept("quantum-physics")
+	a := semix.NewConcept("A", semix.WithEdges(br, p))
+	b := semix.NewConcept("B", semix.WithEdges(br, q))
+	ambig := semix.NewConcept("A-B", semix.WithEdges(split, a, split, b))
+	tests := []struct {
+		opts []Option
+		want string
+	}{
+		{nil, "A-B"},
+		{[]Option{WithDecay(memory.Decay{Type: memory.LinearDecay, Rate: 0.01, Offsets: true})}, "B"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.want, func(t *testing.T) {
+			tokens := make(chan semix.StreamToken)
+			go func() {
+				tokens <- semix.StreamToken{Token: semix.Token{Concept: p, Path: "test", Begin: 0}}
+				tokens <- semix.StreamToken{Token: semix.Token{Concept: q, Path: "test", Begin: 90}}
+				tokens <- semix.StreamToken{Token: semix.Token{Concept: ambig, Path: "test", Begin: 100}}
+				close(tokens)
+			}()
+			var got string
+			for tok := range Resolve(context.TODO(), 3, Automatic{0.3}, tokens, tc.opts...) {
+				if tok.Err != nil {
+					t.Fatalf("go error: %s", tok.Err)
+				}
+				got = tok.Token.Concept.URL()
+			}
+			if got != tc.want {
+				t.Fatalf("expected %s; got %s", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestStreamWithLookahead(t *testing.T) {
+	split := semix.NewConcept(semix.SplitURL)
+	a := semix.NewConcept("A")
+	b := semix.NewConcept("B")
+	ambig := semix.NewConcept("A-B", semix.WithEdges(split, a, split, b))
+	tests := []struct {
+		k    int
+		want string
+	}{
+		{0, "A-B,x,B,B,x,B"},
+		{1, "B,x,B,B,x,B"},
+		{2, "B,x,B,B,x,B"},
+		{3, "B,x,B,B,x,B"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.want, func(t *testing.T) {
+			tokens := make(chan semix.StreamToken)
+			go func() {
+				for i := 0; i < 2; i++ {
+					tokens <- semix.StreamToken{Token: semix.Token{Concept: ambig, Path: "test"}}
+					tokens <- semix.StreamToken{Token: semix.Token{Token: "x", Path: "test"}}
+					tokens <- semix.StreamToken{Token: semix.Token{Concept: b, Path: "test"}}
+				}
+				close(tokens)
+			}()
+			var got []string
+			for tok := range Resolve(context.TODO(), 1, Simple{}, tokens, WithLookahead(tc.k)) {
+				if tok.Err != nil {
+					t.Fatalf("go error: %s", tok.Err)
+				}
+				if tok.Token.Concept == nil {
+					got = append(got, tok.Token.Token)
+				} else {
+					got = append(got, tok.Token.Concept.URL())
+				}
+			}
+			if str := strings.Join(got, ","); str != tc.want {
+				t.Fatalf("expected %s; got %s", tc.want, str)
+			}
+		})
+	}
+}
+
+func TestStreamWithBoundedLookahead(t *testing.T) {
+	split := semix.NewConcept(semix.SplitURL)
+	a := semix.NewConcept("A")
+	b := semix.NewConcept("B")
+	ambig := semix.NewConcept("A-B", semix.WithEdges(split, a, split, b))
+	tests := []struct {
+		name string
+		ts   []semix.Token
+	}{
+		{"end of document", []semix.Token{
+			{Concept: ambig, Path: "test"},
+			{Token: "x", Path: "test"},
+			{Concept: b, Path: "other"},
+		}},
+		{"full queue", append([]semix.Token{{Concept: ambig, Path: "test"}},
+			make([]semix.Token, MaxLookaheadTokens)...)},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			// the stream is not closed until the test ends
+			tokens := make(chan semix.StreamToken)
+			go func() {
+				for _, tok := range tc.ts {
+					select {
+					case <-ctx.Done():
+						return
+					case tokens <- semix.StreamToken{Token: tok}:
+					}
+				}
+			}()
+			select {
+			case tok := <-Resolve(ctx, 1, Simple{}, tokens, WithLookahead(3)):
+				if tok.Token.Concept != ambig {
+					t.Fatalf("expected %s; got %v", ambig, tok.Token.Concept)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("ambiguous token was not resolved")
+			}
+		})
+	}
+}
+
+func TestStreamWithNested(t *testing.T) {
+	split := semix.NewConcept(semix.SplitURL)
+	a := semix.NewConcept("A")
+	b := semix.NewConcept("B")
+	ambig := semix.NewConcept("A-B", semix.WithEdges(split, a, split, b))
+	tokens := []semix.Token{
+		{Concept: a, Path: "test", Begin: 0, End: 9},
+		{Concept: b, Path: "test", Begin: 2, End: 5, Nested: true},
+		{Concept: b, Path: "test", Begin: 6, End: 9, Nested: true},
+		{Concept: ambig, Path: "test", Begin: 10, End: 15},
+		{Concept: ambig, Path: "test", Begin: 12, End: 15, Nested: true},
+		{Concept: b, Path: "test", Begin: 20, End: 25},
+		{Concept: ambig, Path: "test", Begin: 30, End: 35},
+	}
+	var maximal []semix.Token
+	for _, t := range tokens {
+		if !t.Nested {
+			maximal = append(maximal, t)
+		}
+	}
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{"default", nil},
+		{"lookahead", []Option{WithLookahead(2)}},
+		{"offsets", []Option{WithDecay(memory.Decay{Type: memory.LinearDecay, Rate: 0.01, Offsets: true})}},
+		{"vote", []Option{WithOneSensePerDocument(MajorityVote)}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			want := resolveTokens(t, maximal, tc.opts...)
+			if got := resolveTokens(t, tokens, tc.opts...); !reflect.DeepEqual(got, want) {
+				t.Fatalf("expected %v; got %v", want, got)
+			}
+		})
+	}
+}
+
+// resolveTokens resolves the tokens with Simple
+// and returns the resolved tokens that are not nested.
+func resolveTokens(t *testing.T, ts []semix.Token, opts ...Option) []semix.Token {
+	t.Helper()
+	tokens := make(chan semix.StreamToken, len(ts))
+	for _, tok := range ts {
+		tokens <- semix.StreamToken{Token: tok}
+	}
+	close(tokens)
+	var res []semix.Token
+	for tok := range Resolve(context.TODO(), 3, Simple{}, tokens, opts...) {
+		if tok.Err != nil {
+			t.Fatalf("got error: %s", tok.Err)
+		}
+		if !tok.Token.Nested {
+			res = append(res, tok.Token)
+		}
+	}
+	return res
+}
+
 func checkResolve(t *testing.T, got, want *semix.Concept) {
 	t.Helper()
 	if got != want {
 		t.Fatalf("expected %p; got %p", want, got)
 	}
 }
+
+func TestRegistry(t *testing.T) {
+	want := []string{"bayes", "pagerank", "ruled", "simple", "thematic"}
+	for _, name := range want {
+		if !Registered(name) {
+			t.Fatalf("resolver %s is not registered", name)
+		}
+	}
+	tests := []struct {
+		name  string
+		ps    Params
+		rs    Resources
+		iserr bool
+	}{
+		{"simple", nil, Resources{}, false},
+		{"Thematic", Params{"threshold": "0.2"}, Resources{}, false},
+		{"thematic", Params{"threshold": "x"}, Resources{}, true},
+		{"bayes", nil, Resources{}, true},
+		{"bayes", nil, Resources{Model: NewModel(1)}, false},
+		{"pagerank", Params{"iterations": "5", "predicates": "a|b"}, Resources{Graph: semix.NewGraph()}, false},
+		{"pagerank", Params{"iterations": "x"}, Resources{}, true},
+		{"invalid", nil, Resources{}, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := New(tc.name, tc.ps, tc.rs)
+			if tc.iserr {
+				if err == nil {
+					t.Fatalf("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("got error: %s", err)
+			}
+			if r == nil {
+				t.Fatalf("got nil resolver")
+			}
+		})
+	}
+	if a, err := New("thematic", Params{"threshold": "0.2"}, Resources{}); err != nil || a.(Automatic).Threshold != 0.2 {
+		t.Fatalf("invalid thematic resolver: %v %v", a, err)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("expected panic")
+		}
+	}()
+	Register("Simple", nil)
+}
+
+func TestUnregister(t *testing.T) {
+	Register("test", func(Params, Resources) (Interface, error) {
+		return Simple{}, nil
+	})
+	if !Registered("test") {
+		t.Fatalf("resolver test is not registered")
+	}
+	Unregister("Test")
+	if Registered("test") {
+		t.Fatalf("resolver test is still registered")
+	}
+}
+
+func TestCache(t *testing.T) {
+	g := semix.NewGraph()
+	rs := Resources{Graph: g, Cache: NewCache()}
+	ps := Params{"iterations": "5", "predicates": "a|b"}
+	p1, err := New("pagerank", ps, rs)
+	if err != nil {
+		t.Fatalf("got error: %s", err)
+	}
+	if p2, _ := New("pagerank", ps, rs); p1 != p2 {
+		t.Fatalf("expected the cached resolver")
+	}
+	if p2, _ := New("pagerank", Params{"iterations": "6"}, rs); p1 == p2 {
+		t.Fatalf("expected a new resolver for different parameters")
+	}
+	if p2, _ := New("pagerank", ps, Resources{Graph: g}); p1 == p2 {
+		t.Fatalf("expected a new resolver without a cache")
+	}
+	if p2, _ := New("pagerank", Params{"iterations": "5", "predicates": "b|a"}, rs); p1 != p2 {
+		t.Fatalf("expected the cached resolver for reordered predicates")
+	}
+}
+
+func TestCacheConcurrentGet(t *testing.T) {
+	cache := NewCache()
+	var mutex sync.Mutex
+	var n int
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cache.Get("key", func() (Interface, error) {
+				mutex.Lock()
+				defer mutex.Unlock()
+				n++
+				return Simple{}, nil
+			})
+		}()
+	}
+	wg.Wait()
+	if n != 1 {
+		t.Fatalf("expected 1 created resolver; got %d", n)
+	}
+}
+
+func TestCacheEviction(t *testing.T) {
+	cache := NewCache()
+	get := func(i int) bool {
+		var created bool
+		cache.Get(fmt.Sprintf("%d", i), func() (Interface, error) {
+			created = true
+			return Simple{}, nil
+		})
+		return created
+	}
+	for i := 0; i < maxCacheSize; i++ {
+		get(i)
+	}
+	get(0) // 1 is the least recently used resolver
+	if !get(maxCacheSize) {
+		t.Fatalf("expected a new resolver")
+	}
+	if get(maxCacheSize) || get(0) {
+		t.Fatalf("expected the cached resolver")
+	}
+	if !get(1) {
+		t.Fatalf("expected a new resolver for the evicted key")
+	}
+}
+
+func TestParams(t *testing.T) {
+	ps := Params{"f": "0.5", "i": "3", "s": " a | b||c "}
+	if f, err := ps.Float("f", 1); err != nil || f != 0.5 {
+		t.Fatalf("invalid float: %g %v", f, err)
+	}
+	if f, err := ps.Float("x", 1); err != nil || f != 1 {
+		t.Fatalf("invalid default float: %g %v", f, err)
+	}
+	if i, err := ps.Int("i", 1); err != nil || i != 3 {
+		t.Fatalf("invalid int: %d %v", i, err)
+	}
+	if _, err := ps.Int("f", 1); err == nil {
+		t.Fatalf("expected error")
+	}
+	if got := strings.Join(ps.Strings("s"), ","); got != "a,b,c" {
+		t.Fatalf("expected a,b,c; got %s", got)
+	}
+	if got := ps.Strings("x"); len(got) != 0 {
+		t.Fatalf("expected empty list; got %v", got)
+	}
+}
@@ -7,17 +7,24 @@ import (
 )
 
 // Ruled is a resolver that uses the compiled rules to disambiguate concepts.
+// The graph is used by the graph functions of the rules.
 type Ruled struct {
 	Rules rule.Map
+	Graph *semix.Graph
 }
 
 // Resolve is used to resolve ambiguities.
 func (r Ruled) Resolve(c *semix.Concept, mem *memory.Memory) *semix.Concept {
-	return resolve(c, func(c *semix.Concept) float64 {
+	return best(r.Scores(c, mem))
+}
+
+// Scores returns 1 for the possible senses whose rules hold and 0 otherwise.
+func (r Ruled) Scores(c *semix.Concept, mem *memory.Memory) []Candidate {
+	return candidates(c, func(c *semix.Concept) float64 {
 		if _, ok := r.Rules[c.URL()]; !ok {
 			return 0
 		}
-		if r.Rules[c.URL()].Execute(mem) < 1 {
+		if r.Rules[c.URL()].Execute(mem, r.Graph) < 1 {
 			return 0
 		}
 		return 1
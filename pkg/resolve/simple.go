@@ -11,8 +11,14 @@ type Simple struct{}
 
 // Resolve chooses the most occuring concept in the memory
 // that occurres at least once in the memory.
-func (Simple) Resolve(c *semix.Concept, mem *memory.Memory) *semix.Concept {
-	return resolve(c, func(c *semix.Concept) float64 {
+func (s Simple) Resolve(c *semix.Concept, mem *memory.Memory) *semix.Concept {
+	return best(s.Scores(c, mem))
+}
+
+// Scores returns the number of occurrences of
+// the possible senses in the memory.
+func (Simple) Scores(c *semix.Concept, mem *memory.Memory) []Candidate {
+	return candidates(c, func(c *semix.Concept) float64 {
 		return float64(mem.CountIfS(func(cc *semix.Concept) bool {
 			return cc.URL() == c.URL()
 		}))
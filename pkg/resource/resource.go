@@ -12,6 +12,7 @@ import (
 	"strconv"
 	"strings"
 
+	"bitbucket.org/fflo/semix/pkg/morph"
 	"bitbucket.org/fflo/semix/pkg/rdfxml"
 	"bitbucket.org/fflo/semix/pkg/say"
 	"bitbucket.org/fflo/semix/pkg/semix"
@@ -39,6 +40,8 @@ const (
 
 type file struct {
 	Path, Type, Cache, Ambigs string
+	Confusions                string
+	Rules                     []string
 	handle                    semix.HandleAmbigsFunc
 }
 
@@ -53,10 +56,24 @@ type predicates struct {
 	Rule       []string
 }
 
+type variants struct {
+	Languages []string
+	MinStem   int
+	Rules     map[string][]string
+	generator *morph.Generator
+}
+
 // Config represents the configuration for a knowledge base.
+// Normalize configures the normalization of the dictionary
+// entries and the input documents (see semix.Normalizer).
+// Variants configures the generation of inflected variants
+// of the dictionary labels (see package morph). Custom rules
+// replace the builtin rules of their language.
 type Config struct {
 	File       file
 	Predicates predicates
+	Normalize  semix.Normalizer
+	Variants   variants
 }
 
 // Parse is a convinence fuction that parses a knowledge base
@@ -70,8 +87,8 @@ func Parse(file string, useCache bool) (*semix.Resource, error) {
 }
 
 // Read reads a configuration from a file.
-// $VAR and ${VAR} in file.path and file.cache
-// are automatically expanded using the environment.
+// $VAR and ${VAR} in file.path, file.cache, file.confusions and
+// file.rules are automatically expanded using the environment.
 func Read(file string) (*Config, error) {
 	var c Config
 	if _, err := toml.DecodeFile(file, &c); err != nil {
@@ -79,21 +96,44 @@ func Read(file string) (*Config, error) {
 	}
 	c.File.Cache = os.ExpandEnv(c.File.Cache)
 	c.File.Path = os.ExpandEnv(c.File.Path)
+	c.File.Confusions = os.ExpandEnv(c.File.Confusions)
+	for i := range c.File.Rules {
+		c.File.Rules[i] = os.ExpandEnv(c.File.Rules[i])
+	}
 	handle, err := c.newHandle()
 	if err != nil {
 		return nil, err
 	}
 	c.File.handle = handle
+	if err := c.Normalize.Check(); err != nil {
+		return nil, errors.Wrapf(err, "invalid normalization")
+	}
+	gen, err := c.newGenerator()
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid variants")
+	}
+	c.Variants.generator = gen
 	return &c, nil
 }
 
 // Parse parses the configuration and returns the graph and the dictionary.
 // If useCache is false, the cache is neither read nor written.
+// The cache is not used if it was built with another normalization
+// or other variant rules.
 func (c *Config) Parse(useCache bool) (*semix.Resource, error) {
 	if useCache && c.File.Cache != "" {
 		if r, err := c.readCache(); err == nil {
-			logResource(r)
-			return r, nil
+			switch {
+			case !r.Normalizer.Equal(c.Normalize):
+				say.Info("cache %s uses normalization %s; ignoring cache",
+					c.File.Cache, r.Normalizer)
+			case r.VariantRules != c.variantRules():
+				say.Info("cache %s uses variant rules %q; ignoring cache",
+					c.File.Cache, r.VariantRules)
+			default:
+				logResource(r)
+				return r, nil
+			}
 		}
 	}
 	is, err := os.Open(c.File.Path)
@@ -105,7 +145,11 @@ func (c *Config) Parse(useCache bool) (*semix.Resource, error) {
 	if err != nil {
 		return nil, err
 	}
-	r, err := semix.Parse(parser, c.Traits())
+	opts := []semix.ParseOption{semix.WithNormalizer(c.Normalize)}
+	if c.Variants.generator != nil {
+		opts = append(opts, semix.WithVariants(c.Variants.generator))
+	}
+	r, err := semix.Parse(parser, c.Traits(), opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -118,9 +162,104 @@ func (c *Config) Parse(useCache bool) (*semix.Resource, error) {
 	return r, nil
 }
 
+// EditCosts returns the edit costs of the approximate matching that are
+// read from the confusion table file.confusions (see semix.ReadEditCosts).
+// If no confusion table is configured, the default edit costs are returned.
+func (c *Config) EditCosts() (semix.EditCosts, error) {
+	if c.File.Confusions == "" {
+		return semix.DefaultEditCosts(), nil
+	}
+	return ReadEditCosts(c.File.Confusions)
+}
+
+// ReadEditCosts reads the edit costs from the confusion table at the given path.
+func ReadEditCosts(path string) (semix.EditCosts, error) {
+	is, err := os.Open(path)
+	if err != nil {
+		return semix.EditCosts{}, errors.Wrapf(err, "cannot read confusion table")
+	}
+	defer func() { _ = is.Close() }()
+	costs, err := semix.ReadEditCosts(is)
+	if err != nil {
+		return semix.EditCosts{}, errors.Wrapf(err, "cannot read confusion table %s", path)
+	}
+	return costs, nil
+}
+
+// ConfusionTableExt is the extension of confusion table files.
+const ConfusionTableExt = ".confusions"
+
+// ReadConfusionTables reads the edit costs of all confusion tables
+// in the given directory. The tables are named by the base names of
+// their files without the extension ConfusionTableExt.
+func ReadConfusionTables(dir string) (map[string]semix.EditCosts, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*"+ConfusionTableExt))
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot read confusion tables")
+	}
+	tables := make(map[string]semix.EditCosts, len(paths))
+	for _, path := range paths {
+		costs, err := ReadEditCosts(path)
+		if err != nil {
+			return nil, err
+		}
+		tables[strings.TrimSuffix(filepath.Base(path), ConfusionTableExt)] = costs
+	}
+	return tables, nil
+}
+
+// ModelPath returns the path of the trained model of the resolver
+// with the given name. Models are stored next to the cache.
+func (c *Config) ModelPath(name string) (string, error) {
+	if c.File.Cache == "" {
+		return "", fmt.Errorf("cannot locate model %s: no cache", name)
+	}
+	ext := filepath.Ext(c.File.Cache)
+	return strings.TrimSuffix(c.File.Cache, ext) + "." + name + ext, nil
+}
+
 func logResource(r *semix.Resource) {
-	say.Debug("loaded %d concepts, %d entries, %d rules",
-		r.Graph.ConceptsLen(), len(r.Dictionary), len(r.Rules))
+	say.Debug("loaded %d concepts, %d entries, %d variants, %d rules (normalization: %s)",
+		r.Graph.ConceptsLen(), len(r.Dictionary), len(r.Variants), len(r.Rules), r.Normalizer)
+}
+
+// newGenerator returns the variant generator of the configuration
+// or nil if no variants should be generated.
+func (c *Config) newGenerator() (*morph.Generator, error) {
+	if len(c.Variants.Languages) == 0 {
+		return nil, nil
+	}
+	minStem := c.Variants.MinStem
+	if minStem <= 0 {
+		minStem = morph.DefaultMinStem
+	}
+	rules := make(map[string]morph.Rules, len(c.Variants.Languages))
+	for _, lang := range c.Variants.Languages {
+		lang = strings.ToLower(lang)
+		if strs, ok := c.Variants.Rules[lang]; ok {
+			rs, err := morph.ParseRules(strs...)
+			if err != nil {
+				return nil, err
+			}
+			rules[lang] = rs
+			continue
+		}
+		rs, err := morph.Builtin(lang)
+		if err != nil {
+			return nil, err
+		}
+		rules[lang] = rs
+	}
+	return morph.NewGenerator(rules, minStem), nil
+}
+
+// variantRules returns the description of the variant rules
+// or the empty string if no variants are generated.
+func (c *Config) variantRules() string {
+	if c.Variants.generator == nil {
+		return ""
+	}
+	return c.Variants.generator.String()
 }
 
 // Traits returns a new Traits interface using the configuration
@@ -1,10 +1,16 @@
 package resource
 
 import (
+	"os"
+	"reflect"
+	"strings"
 	"testing"
 )
 
 func TestConfig(t *testing.T) {
+	if err := os.Setenv("SEMIX_TEST_DIR", "testdata"); err != nil {
+		t.Fatalf("got error: %s", err)
+	}
 	c, err := Read("testdata/test.toml")
 	if err != nil {
 		t.Fatalf("got error: %s", err)
@@ -18,6 +24,32 @@ func TestConfig(t *testing.T) {
 	if got := c.File.Cache; got != "/tmp/test.cache" {
 		t.Fatalf("invalid config file cache: %s", got)
 	}
+	if got := c.File.Rules; len(got) != 1 || got[0] != "testdata/test.rules" {
+		t.Fatalf("invalid config file rules: %v", got)
+	}
+	if got, _ := c.ModelPath("bayes"); got != "/tmp/test.bayes.cache" {
+		t.Fatalf("invalid model path: %s", got)
+	}
+	costs, err := c.EditCosts()
+	if err != nil {
+		t.Fatalf("got error: %s", err)
+	}
+	if costs.Substitute != 2 || len(costs.Confusions) != 2 {
+		t.Fatalf("invalid edit costs: %v", costs)
+	}
+	tables, err := ReadConfusionTables("testdata")
+	if err != nil {
+		t.Fatalf("got error: %s", err)
+	}
+	if len(tables) != 1 || !reflect.DeepEqual(tables["test"], costs) {
+		t.Fatalf("invalid confusion tables: %v", tables)
+	}
+	if got := c.Normalize.String(); got != "map(ſ=s) nfkc fold" {
+		t.Fatalf("invalid normalization: %s", got)
+	}
+	if got := c.variantRules(); got != "de:e/en en:/s/!(s|x|z|ch|sh|[^aeiou]y),/es/(s|x|z|ch|sh),y/ies/[^aeiou]y minstem=4" {
+		t.Fatalf("invalid variant rules: %s", got)
+	}
 	traits := c.Traits()
 	if !traits.IsTransitive("http://example.org/transitive") {
 		t.Fatalf("missing transitive predicate")
@@ -44,3 +76,66 @@ func TestConfig(t *testing.T) {
 		t.Fatalf("invalid handle ambigs function returned")
 	}
 }
+
+func TestReadRules(t *testing.T) {
+	tests := []struct {
+		paths []string
+		want  map[string]string
+	}{
+		{[]string{"testdata/test.rules"}, map[string]string{
+			"http://example.org/a": `cs("http://example.org/b") > 0`,
+			"http://example.org/b": `c("http://example.org/a")>1 && c("http://example.org/c")>0`,
+		}},
+		{[]string{"testdata/test.rules", "testdata/override.rules"}, map[string]string{
+			"http://example.org/a": `cs("http://example.org/b") > 0`,
+			"http://example.org/b": `cs("http://example.org/c") > 0`,
+		}},
+	}
+	for _, tc := range tests {
+		t.Run(strings.Join(tc.paths, ","), func(t *testing.T) {
+			rules, err := ReadRules(tc.paths...)
+			if err != nil {
+				t.Fatalf("got error: %s", err)
+			}
+			if len(rules) != len(tc.want) {
+				t.Fatalf("expected %d rules; got %d", len(tc.want), len(rules))
+			}
+			for url, want := range tc.want {
+				if got := rules[url]; got != want {
+					t.Fatalf("expected %q; got %q", want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestMergeRules(t *testing.T) {
+	base := map[string]string{
+		"http://example.org/b": `c("http://example.org/b") > 0`,
+		"http://example.org/c": `c("http://example.org/a") > 0`,
+	}
+	rules, err := MergeRules(base, "testdata/override.rules")
+	if err != nil {
+		t.Fatalf("got error: %s", err)
+	}
+	want := map[string]string{
+		"http://example.org/b": `cs("http://example.org/c") > 0`,
+		"http://example.org/c": `c("http://example.org/a") > 0`,
+	}
+	if !reflect.DeepEqual(rules, want) {
+		t.Fatalf("expected %v; got %v", want, rules)
+	}
+	if got := base["http://example.org/b"]; got != `c("http://example.org/b") > 0` {
+		t.Fatalf("base rules modified: %q", got)
+	}
+}
+
+func TestReadRulesInvalid(t *testing.T) {
+	for _, tc := range []string{"http://example.org/a", "http://example.org/a  \t"} {
+		t.Run(tc, func(t *testing.T) {
+			if err := readRules(strings.NewReader(tc), "test", map[string]string{}); err == nil {
+				t.Fatalf("expected error")
+			}
+		})
+	}
+}
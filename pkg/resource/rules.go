@@ -0,0 +1,67 @@
+package resource
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ReadRules reads the rules from the given rule files.
+// Each non empty line of a rule file that does not start with a
+// '#' must consist of a concept URL followed by white space and
+// the rule's expression. Rules in later files override
+// rules for the same concept in earlier files.
+func ReadRules(paths ...string) (map[string]string, error) {
+	rules := make(map[string]string)
+	for _, path := range paths {
+		if err := readRulesFile(path, rules); err != nil {
+			return nil, err
+		}
+	}
+	return rules, nil
+}
+
+// MergeRules returns the rules of base merged with the rules of the
+// given rule files. The rules in the files override the rules in base.
+// The map base is not modified.
+func MergeRules(base map[string]string, paths ...string) (map[string]string, error) {
+	frs, err := ReadRules(paths...)
+	if err != nil {
+		return nil, err
+	}
+	rules := make(map[string]string, len(base)+len(frs))
+	for url, str := range base {
+		rules[url] = str
+	}
+	for url, str := range frs {
+		rules[url] = str
+	}
+	return rules, nil
+}
+
+func readRulesFile(path string, rules map[string]string) error {
+	is, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = is.Close() }()
+	return readRules(is, path, rules)
+}
+
+func readRules(r io.Reader, path string, rules map[string]string) error {
+	s := bufio.NewScanner(r)
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		i := strings.IndexAny(line, " \t")
+		if i <= 0 || strings.TrimSpace(line[i:]) == "" {
+			return fmt.Errorf("invalid rule: %s:%d: %s", path, n, line)
+		}
+		rules[line[:i]] = strings.TrimSpace(line[i:])
+	}
+	return s.Err()
+}
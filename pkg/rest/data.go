@@ -3,9 +3,12 @@ package rest
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
+	"unicode"
 
 	"bitbucket.org/fflo/semix/pkg/index"
+	"bitbucket.org/fflo/semix/pkg/memory"
 	"bitbucket.org/fflo/semix/pkg/resolve"
 	"bitbucket.org/fflo/semix/pkg/rule"
 	"bitbucket.org/fflo/semix/pkg/semix"
@@ -17,7 +20,12 @@ type DumpFileContent struct {
 	ContentType, Content, Path string
 }
 
-// PutData defines the data that is send to the server's put method
+// PutData defines the data that is send to the server's put method.
+// If Nested is set, nested and overlapping matches are reported
+// as well (see semix.MatchAll). Matcher selects the exact matcher
+// (DFAMatcher or AhoCorasickMatcher); the default is DFAMatcher.
+// Confusions names a confusion table of the server that replaces
+// the default edit costs of the server (see WithConfusionTables).
 type PutData struct {
 	URL         string
 	Local       bool
@@ -25,12 +33,24 @@ type PutData struct {
 	Resolvers   []Resolver
 	ContentType string
 	Content     string
+	Nested      bool   `json:",omitempty"`
+	Matcher     string `json:",omitempty"`
+	Confusions  string `json:",omitempty"`
 }
 
+// Names of the exact matchers.
+const (
+	DFAMatcher         = "dfa"
+	AhoCorasickMatcher = "ahocorasick"
+)
+
 func (p PutData) stream(
 	ctx context.Context,
 	dfa semix.DFA,
-	rules rule.Map,
+	ac semix.AhoCorasick,
+	costs semix.EditCosts,
+	norm semix.Normalizer,
+	res resolve.Resources,
 	idx index.Putter,
 	dir string,
 ) (semix.Stream, error) {
@@ -38,41 +58,92 @@ func (p PutData) stream(
 	if err != nil {
 		return nil, err
 	}
-	s := p.matchStream(ctx, dfa, semix.Normalize(ctx, semix.Read(ctx, doc)))
-	s, err = p.resolveStream(ctx, rules, s)
+	s, err := p.MatchStream(ctx, dfa, ac, costs, norm.Normalize(ctx, semix.Read(ctx, doc)))
+	if err != nil {
+		return nil, err
+	}
+	s, err = p.ResolveStream(ctx, res, s)
 	if err != nil {
 		return nil, err
 	}
 	return index.Put(ctx, idx, s), nil
 }
 
-func (p PutData) matchStream(
+// MatchStream matches the tokens of the stream using the selected
+// exact matcher. For each of the error limits, an additional fuzzy
+// matcher with the given edit costs is used for the unmatched tokens.
+// Nested matches are only reported by the exact matcher.
+func (p PutData) MatchStream(
 	ctx context.Context,
 	dfa semix.DFA,
+	ac semix.AhoCorasick,
+	costs semix.EditCosts,
 	s semix.Stream,
-) semix.Stream {
+) (semix.Stream, error) {
+	m, err := p.matcher(dfa, ac)
+	if err != nil {
+		return nil, err
+	}
 	for i := len(p.Errors); i > 0; i-- {
 		l := p.Errors[i-1]
 		if l <= 0 {
 			continue
 		}
-		s = semix.Match(
-			ctx, semix.FuzzyDFAMatcher{DFA: semix.NewFuzzyDFA(l, dfa)}, s)
+		if l > semix.MaxErrorLimit {
+			return nil, errors.Errorf("invalid error limit: %d", l)
+		}
+		s = semix.Match(ctx, semix.FuzzyDFAMatcher{
+			DFA: semix.NewFuzzyDFA(l, dfa, semix.WithEditCosts(costs)),
+		}, s)
 	}
-	return semix.Match(ctx, semix.DFAMatcher{DFA: dfa}, s)
+	if p.Nested {
+		return semix.MatchAll(ctx, m, s), nil
+	}
+	return semix.Match(ctx, m, s), nil
 }
 
-func (p PutData) resolveStream(
+func (p PutData) matcher(dfa semix.DFA, ac semix.AhoCorasick) (semix.AllMatcher, error) {
+	switch strings.ToLower(p.Matcher) {
+	case "", DFAMatcher:
+		return semix.DFAMatcher{DFA: dfa}, nil
+	case AhoCorasickMatcher:
+		return semix.AhoCorasickMatcher{AC: ac}, nil
+	default:
+		return nil, errors.Errorf("invalid matcher: %s", p.Matcher)
+	}
+}
+
+// ResolveStream resolves the ambiguities of the stream
+// using the resolvers in their given order.
+func (p PutData) ResolveStream(
 	ctx context.Context,
-	rules rule.Map,
+	res resolve.Resources,
 	s semix.Stream,
 ) (semix.Stream, error) {
 	for i := len(p.Resolvers); i > 0; i-- {
-		resolver, err := p.Resolvers[i-1].resolver(rules)
+		resolver, err := p.Resolvers[i-1].resolver(res)
+		if err != nil {
+			return nil, err
+		}
+		decay, err := memory.NewDecay(p.Resolvers[i-1].Decay,
+			p.Resolvers[i-1].DecayRate, p.Resolvers[i-1].DecayOffsets)
+		if err != nil {
+			return nil, err
+		}
+		vote, err := resolve.NewVote(p.Resolvers[i-1].Vote)
 		if err != nil {
 			return nil, err
 		}
-		s = resolve.Resolve(ctx, p.Resolvers[i-1].MemorySize, resolver, s)
+		opts := []resolve.Option{
+			resolve.WithName(p.Resolvers[i-1].Name),
+			resolve.WithDecay(decay),
+			resolve.WithLookahead(p.Resolvers[i-1].Lookahead),
+			resolve.WithOneSensePerDocument(vote),
+		}
+		if p.Resolvers[i-1].VoteWindow > 0 {
+			opts = append(opts, resolve.WithVoteWindow(p.Resolvers[i-1].VoteWindow))
+		}
+		s = resolve.Resolve(ctx, p.Resolvers[i-1].MemorySize, resolver, s, opts...)
 	}
 	return s, nil
 }
@@ -94,12 +165,40 @@ func (p PutData) document(dir string) (semix.Document, error) {
 	return doc, nil
 }
 
-// Resolver defines one of the three resolvers simple, automatic or ruled
-// as defined in bitbucket.org/fflo/semix/pkg/resolve
+// Resolver defines a resolver that is registered in
+// bitbucket.org/fflo/semix/pkg/resolve or an ensemble of resolvers.
+// Params holds the parameters of the resolver. If set,
+// Threshold is given to the resolver as parameter threshold.
+// Decay, DecayRate and DecayOffsets define the decay of the
+// weights of the resolver's memory (see bitbucket.org/fflo/semix/pkg/memory).
+// Lookahead sets the number of following tokens that are
+// used as right context. Vote enables the one sense per document
+// mode with the given vote (majority or score). A positive VoteWindow
+// limits the number of tokens of a document that are buffered for
+// the vote; by default whole documents are buffered.
+//
+// Iterations and Predicates are deprecated. Use the parameters
+// iterations and predicates of the pagerank resolver instead.
+//
+// An ensemble combines the scores of its members using the members'
+// positive weights (an omitted weight is treated as 1). The Threshold of an ensemble
+// defines the minimal confidence of a resolved concept. The memory
+// settings of the members are ignored.
 type Resolver struct {
-	Name       string
-	Threshold  float64
-	MemorySize int
+	Name         string
+	Threshold    float64
+	MemorySize   int
+	Decay        string
+	DecayRate    float64
+	DecayOffsets bool
+	Lookahead    int
+	Vote         string            `json:",omitempty"`
+	VoteWindow   int               `json:",omitempty"`
+	Iterations   int               `json:",omitempty"`
+	Predicates   []string          `json:",omitempty"`
+	Params       map[string]string `json:",omitempty"`
+	Weight       float64           `json:",omitempty"`
+	Members      []Resolver        `json:",omitempty"`
 }
 
 // Names for the different resolver types.
@@ -107,46 +206,190 @@ const (
 	ThematicResolver = "thematic"
 	RuledResolver    = "ruled"
 	SimpleResolver   = "simple"
+	BayesResolver    = "bayes"
+	EnsembleResolver = "ensemble"
+	DocumentResolver = "document"
+	PageRankResolver = "pagerank"
 )
 
 // MakeResolvers is a simple helper function to build resolvers from a list of strings.
+// Resolvers are given by their registered names with optional parameters,
+// e.g. "pagerank(iterations=10 predicates=http://example.org/p|http://example.org/q)".
+// Ensembles are given as "ensemble(name[:weight] ...)", e.g.
+// "ensemble(thematic:0.5 simple ruled:2)". "document(name)" resolves
+// the ambiguities of the given resolver with one sense per document
+// using a majority vote. The threshold t is used for thematic resolvers.
 func MakeResolvers(t float64, m int, rs []string) ([]Resolver, error) {
 	res := make([]Resolver, len(rs))
 	for i, r := range rs {
-		switch strings.ToLower(r) {
-		case ThematicResolver:
-			res[i] = Resolver{
-				Name:       ThematicResolver,
-				MemorySize: m,
-				Threshold:  t,
-			}
-		case RuledResolver:
-			res[i] = Resolver{
-				Name:       RuledResolver,
-				MemorySize: m,
+		x, err := makeResolver(t, m, strings.TrimSpace(r))
+		if err != nil {
+			return nil, err
+		}
+		res[i] = x
+	}
+	return res, nil
+}
+
+func makeResolver(t float64, m int, r string) (Resolver, error) {
+	name, args, ok := splitArgs(r)
+	if !ok {
+		name = strings.ToLower(r)
+	}
+	switch name {
+	case EnsembleResolver:
+		e, err := makeEnsemble(t, m, args)
+		if err != nil {
+			return Resolver{}, errors.Wrapf(err, "invalid ensemble: %s", r)
+		}
+		return e, nil
+	case DocumentResolver:
+		d, err := makeResolver(t, m, strings.TrimSpace(args))
+		if err != nil {
+			return Resolver{}, errors.Wrapf(err, "invalid document resolver: %s", r)
+		}
+		d.Vote = string(resolve.MajorityVote)
+		return d, nil
+	}
+	if !resolve.Registered(name) {
+		return Resolver{}, errors.Errorf("invalid resolver name: %s", r)
+	}
+	res := Resolver{Name: name, MemorySize: m}
+	if name == ThematicResolver {
+		res.Threshold = t
+	}
+	for _, arg := range strings.Fields(args) {
+		pos := strings.Index(arg, "=")
+		if pos <= 0 {
+			return Resolver{}, errors.Errorf("invalid parameter %q: %s", arg, r)
+		}
+		if res.Params == nil {
+			res.Params = make(map[string]string)
+		}
+		res.Params[arg[:pos]] = arg[pos+1:]
+	}
+	return res, nil
+}
+
+// splitArgs splits "name(args)" into its lower case name and its arguments.
+func splitArgs(r string) (string, string, bool) {
+	pos := strings.Index(r, "(")
+	if pos == -1 || !strings.HasSuffix(r, ")") {
+		return "", "", false
+	}
+	return strings.ToLower(strings.TrimSpace(r[:pos])), r[pos+1 : len(r)-1], true
+}
+
+// splitFields splits the string around white space
+// that is not enclosed in parentheses.
+func splitFields(str string) []string {
+	var fields []string
+	var depth, start int
+	for i, r := range str {
+		switch {
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+		case unicode.IsSpace(r) && depth == 0:
+			if start < i {
+				fields = append(fields, str[start:i])
 			}
-		case SimpleResolver:
-			res[i] = Resolver{
-				Name:       SimpleResolver,
-				MemorySize: m,
+			start = i + 1
+		}
+	}
+	if start < len(str) {
+		fields = append(fields, str[start:])
+	}
+	return fields
+}
+
+func makeEnsemble(t float64, m int, args string) (Resolver, error) {
+	e := Resolver{Name: EnsembleResolver, MemorySize: m}
+	for _, arg := range splitFields(args) {
+		name, weight := arg, 1.0
+		if pos := strings.LastIndex(arg, ":"); pos != -1 && pos > strings.LastIndex(arg, ")") {
+			w, err := strconv.ParseFloat(arg[pos+1:], 64)
+			if err != nil || w <= 0 {
+				return Resolver{}, errors.Errorf("invalid weight: %s", arg)
 			}
-		default:
-			return nil, errors.Errorf("invalid resolver name: %s", r)
+			name, weight = arg[:pos], w
 		}
+		if n, _, ok := splitArgs(name); (ok && (n == EnsembleResolver || n == DocumentResolver)) ||
+			strings.ToLower(name) == EnsembleResolver {
+			return Resolver{}, errors.Errorf("nested ensemble: %s", arg)
+		}
+		r, err := makeResolver(t, m, name)
+		if err != nil {
+			return Resolver{}, err
+		}
+		r.Weight = weight
+		e.Members = append(e.Members, r)
 	}
-	return res, nil
+	if len(e.Members) == 0 {
+		return Resolver{}, errors.New("no members")
+	}
+	return e, nil
 }
 
-func (r Resolver) resolver(rules rule.Map) (resolve.Interface, error) {
-	switch strings.ToLower(r.Name) {
-	case ThematicResolver:
-		return resolve.Automatic{Threshold: r.Threshold}, nil
-	case SimpleResolver:
-		return resolve.Simple{}, nil
-	case RuledResolver:
-		return resolve.Ruled{Rules: rules}, nil
+func (r Resolver) resolver(res resolve.Resources) (resolve.Interface, error) {
+	if strings.ToLower(r.Name) == EnsembleResolver {
+		return r.ensemble(res)
 	}
-	return nil, fmt.Errorf("invalid resolver name: %s", r.Name)
+	return resolve.New(r.Name, r.params(), res)
+}
+
+// params returns the parameters of the resolver
+// including the threshold and the deprecated fields.
+func (r Resolver) params() resolve.Params {
+	ps := make(resolve.Params, len(r.Params)+3)
+	for k, v := range r.Params {
+		ps[k] = v
+	}
+	setParam := func(key, val string) {
+		if _, ok := ps[key]; !ok {
+			ps[key] = val
+		}
+	}
+	if r.Threshold != 0 {
+		setParam("threshold", strconv.FormatFloat(r.Threshold, 'g', -1, 64))
+	}
+	if r.Iterations > 0 {
+		setParam("iterations", strconv.Itoa(r.Iterations))
+	}
+	if len(r.Predicates) > 0 {
+		setParam("predicates", strings.Join(r.Predicates, "|"))
+	}
+	return ps
+}
+
+func (r Resolver) ensemble(res resolve.Resources) (resolve.Interface, error) {
+	e := resolve.Ensemble{Threshold: r.Threshold}
+	if len(r.Members) == 0 {
+		return nil, fmt.Errorf("no members for resolver: %s", r.Name)
+	}
+	for _, m := range r.Members {
+		if strings.ToLower(m.Name) == EnsembleResolver {
+			return nil, fmt.Errorf("nested ensemble in resolver: %s", r.Name)
+		}
+		member, err := m.resolver(res)
+		if err != nil {
+			return nil, err
+		}
+		scorer, ok := member.(resolve.Scorer)
+		if !ok {
+			return nil, fmt.Errorf("resolver %s cannot be used in an ensemble", m.Name)
+		}
+		weight := m.Weight
+		if weight < 0 {
+			return nil, fmt.Errorf("invalid weight %g for resolver: %s", weight, m.Name)
+		}
+		if weight == 0 { // omitted
+			weight = 1
+		}
+		e.Members = append(e.Members, resolve.Member{Scorer: scorer, Weight: weight})
+	}
+	return e, nil
 }
 
 // ConceptInfo holds information about a concept.
@@ -167,8 +410,30 @@ func (info ConceptInfo) Predicates() map[*semix.Concept][]*semix.Concept {
 	return m
 }
 
-// Context specifies the context of a match
+// RuleTrace holds the score and the trace of an evaluated rule.
+type RuleTrace struct {
+	URL, Rule string
+	Score     float64
+	Trace     []rule.Step
+}
+
+// RuleError holds the compile error of a rule.
+type RuleError struct {
+	URL, Rule, Error string
+}
+
+// RulesReload holds the number of loaded rules and
+// the errors of the rules that could not be compiled.
+type RulesReload struct {
+	Rules  int
+	Errors []RuleError
+}
+
+// Context specifies the context of a match. If Original is set,
+// Begin and End are the positions in the original document.
+// Otherwise they are the positions in the normalized document.
 type Context struct {
 	Before, Match, After, URL string
 	Begin, End, Len           int
+	Original                  bool
 }
@@ -0,0 +1,147 @@
+package rest
+
+import (
+	"reflect"
+	"testing"
+
+	"bitbucket.org/fflo/semix/pkg/memory"
+	"bitbucket.org/fflo/semix/pkg/resolve"
+	"bitbucket.org/fflo/semix/pkg/semix"
+)
+
+func TestMakeResolvers(t *testing.T) {
+	tests := []struct {
+		test  []string
+		want  []Resolver
+		iserr bool
+	}{
+		{[]string{"simple", "Thematic", "pagerank"}, []Resolver{
+			{Name: SimpleResolver, MemorySize: 5},
+			{Name: ThematicResolver, MemorySize: 5, Threshold: 0.5},
+			{Name: PageRankResolver, MemorySize: 5},
+		}, false},
+		{[]string{"ensemble(thematic:0.5 simple ruled:2)"}, []Resolver{
+			{Name: EnsembleResolver, MemorySize: 5, Members: []Resolver{
+				{Name: ThematicResolver, MemorySize: 5, Threshold: 0.5, Weight: 0.5},
+				{Name: SimpleResolver, MemorySize: 5, Weight: 1},
+				{Name: RuledResolver, MemorySize: 5, Weight: 2},
+			}},
+		}, false},
+		{[]string{"document(simple)", "document( ensemble(simple ruled) )"}, []Resolver{
+			{Name: SimpleResolver, MemorySize: 5, Vote: "majority"},
+			{Name: EnsembleResolver, MemorySize: 5, Vote: "majority", Members: []Resolver{
+				{Name: SimpleResolver, MemorySize: 5, Weight: 1},
+				{Name: RuledResolver, MemorySize: 5, Weight: 1},
+			}},
+		}, false},
+		{[]string{"pagerank(iterations=10 predicates=http://example.org/p|q)", "Simple()"}, []Resolver{
+			{Name: PageRankResolver, MemorySize: 5, Params: map[string]string{
+				"iterations": "10",
+				"predicates": "http://example.org/p|q",
+			}},
+			{Name: SimpleResolver, MemorySize: 5},
+		}, false},
+		{[]string{"ensemble(thematic(threshold=0.2):2 pagerank(predicates=http://example.org/p))"}, []Resolver{
+			{Name: EnsembleResolver, MemorySize: 5, Members: []Resolver{
+				{Name: ThematicResolver, MemorySize: 5, Threshold: 0.5, Weight: 2,
+					Params: map[string]string{"threshold": "0.2"}},
+				{Name: PageRankResolver, MemorySize: 5, Weight: 1,
+					Params: map[string]string{"predicates": "http://example.org/p"}},
+			}},
+		}, false},
+		{[]string{"invalid"}, nil, true},
+		{[]string{"invalid(a=b)"}, nil, true},
+		{[]string{"ensemble(document(simple))"}, nil, true},
+		{[]string{"document()"}, nil, true},
+		{[]string{"document(simple ruled)"}, nil, true},
+		{[]string{"ensemble()"}, nil, true},
+		{[]string{"ensemble(simple:x)"}, nil, true},
+		{[]string{"ensemble(simple:0)"}, nil, true},
+		{[]string{"ensemble(simple:-1)"}, nil, true},
+		{[]string{"ensemble(invalid)"}, nil, true},
+		{[]string{"ensemble(ensemble(simple))"}, nil, true},
+		{[]string{"simple(ruled)"}, nil, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.test[0], func(t *testing.T) {
+			got, err := MakeResolvers(0.5, 5, tc.test)
+			if tc.iserr {
+				if err == nil {
+					t.Fatalf("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("got error: %s", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("expected %v; got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestEnsembleResolver(t *testing.T) {
+	rs, err := MakeResolvers(0.5, 5, []string{"ensemble(simple thematic:2)"})
+	if err != nil {
+		t.Fatalf("got error: %s", err)
+	}
+	if _, err := rs[0].resolver(resolve.Resources{}); err != nil {
+		t.Fatalf("got error: %s", err)
+	}
+	rs, err = MakeResolvers(0.5, 5, []string{"ensemble(bayes)"})
+	if err != nil {
+		t.Fatalf("got error: %s", err)
+	}
+	if _, err := rs[0].resolver(resolve.Resources{}); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+func TestResolverParams(t *testing.T) {
+	tests := []struct {
+		test Resolver
+		want resolve.Params
+	}{
+		{Resolver{Name: PageRankResolver}, resolve.Params{}},
+		{Resolver{Name: PageRankResolver, Iterations: 3, Predicates: []string{"a", "b"}},
+			resolve.Params{"iterations": "3", "predicates": "a|b"}},
+		{Resolver{Name: PageRankResolver, Iterations: 3, Params: map[string]string{"iterations": "4"}},
+			resolve.Params{"iterations": "4"}},
+		{Resolver{Name: ThematicResolver, Threshold: 0.5}, resolve.Params{"threshold": "0.5"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.test.Name, func(t *testing.T) {
+			if got := tc.test.params(); !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("expected %v; got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+type testResolver struct {
+	url string
+}
+
+func (r testResolver) Resolve(c *semix.Concept, mem *memory.Memory) *semix.Concept {
+	return semix.NewConcept(r.url)
+}
+
+func TestRegisteredResolver(t *testing.T) {
+	resolve.Register("rest-test", func(ps resolve.Params, _ resolve.Resources) (resolve.Interface, error) {
+		return testResolver{url: ps["url"] + ps["threshold"]}, nil
+	})
+	t.Cleanup(func() { resolve.Unregister("rest-test") })
+	rs, err := MakeResolvers(0.5, 5, []string{"rest-test(url=http://example.org/)"})
+	if err != nil {
+		t.Fatalf("got error: %s", err)
+	}
+	rs[0].Threshold = 0.25
+	r, err := rs[0].resolver(resolve.Resources{})
+	if err != nil {
+		t.Fatalf("got error: %s", err)
+	}
+	if got, want := r.Resolve(nil, nil).URL(), "http://example.org/0.25"; got != want {
+		t.Fatalf("expected %s; got %s", want, got)
+	}
+}
@@ -11,6 +11,7 @@ import (
 
 	"bitbucket.org/fflo/semix/pkg/index"
 	"bitbucket.org/fflo/semix/pkg/query"
+	"bitbucket.org/fflo/semix/pkg/resolve"
 	"bitbucket.org/fflo/semix/pkg/rule"
 	"bitbucket.org/fflo/semix/pkg/say"
 	"bitbucket.org/fflo/semix/pkg/searcher"
@@ -24,11 +25,18 @@ type lookupData struct {
 }
 
 type handle struct {
-	searcher  searcher.Searcher
-	index     index.Interface
-	dir, host string
-	dfa       semix.DFA
-	rules     rule.Map
+	searcher   searcher.Searcher
+	index      index.Interface
+	dir, host  string
+	dfa        semix.DFA
+	ac         semix.AhoCorasick
+	costs      semix.EditCosts
+	confusions map[string]semix.EditCosts
+	norm       semix.Normalizer
+	graph      *semix.Graph
+	rules      *ruleSet
+	model      *resolve.Model
+	cache      *resolve.Cache
 }
 
 func requestFunc(h func(*http.Request) (interface{}, int, error)) http.HandlerFunc {
@@ -127,7 +135,17 @@ func (h handle) put(r *http.Request) (interface{}, int, error) {
 	}
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-	stream, err := data.stream(ctx, h.dfa, h.rules, h.index, h.dir)
+	costs := h.costs
+	if data.Confusions != "" {
+		table, ok := h.confusions[data.Confusions]
+		if !ok {
+			return nil, http.StatusBadRequest,
+				fmt.Errorf("invalid confusion table: %s", data.Confusions)
+		}
+		costs = table
+	}
+	res := resolve.Resources{Graph: h.graph, Rules: h.rules.get(), Model: h.model, Cache: h.cache}
+	stream, err := data.stream(ctx, h.dfa, h.ac, costs, h.norm, res, h.index, h.dir)
 	if err != nil {
 		return nil, http.StatusBadRequest, err
 	}
@@ -137,12 +155,17 @@ func (h handle) put(r *http.Request) (interface{}, int, error) {
 			return nil, http.StatusInternalServerError,
 				errors.Wrapf(t.Err, "cannot index document")
 		}
+		obegin, oend := t.Token.Original()
 		es = append(es, index.Entry{
-			Path:       t.Token.Path,
-			Token:      t.Token.Token,
-			ConceptURL: t.Token.Concept.URL(),
-			Begin:      t.Token.Begin,
-			End:        t.Token.End,
+			Path:          t.Token.Path,
+			Token:         t.Token.Token,
+			ConceptURL:    t.Token.Concept.URL(),
+			Begin:         t.Token.Begin,
+			End:           t.Token.End,
+			OriginalBegin: obegin,
+			OriginalEnd:   oend,
+			Variant:       t.Token.Variant,
+			Nested:        t.Token.Nested,
 		})
 	}
 	return es, http.StatusCreated, nil
@@ -150,18 +173,27 @@ func (h handle) put(r *http.Request) (interface{}, int, error) {
 
 func (h handle) get(r *http.Request) (interface{}, int, error) {
 	var data struct {
-		Q    string
-		N, S int
+		Q      string
+		N, S   int
+		M      float64
+		Nested string
 	}
 	if err := DecodeQuery(r.URL.Query(), &data); err != nil {
 		return nil, http.StatusBadRequest, fmt.Errorf("invalid query: %s", err)
 	}
+	nested, err := nestedFilter(data.Nested)
+	if err != nil {
+		return nil, http.StatusBadRequest, fmt.Errorf("invalid query: %s", err)
+	}
 	q, err := query.New(data.Q, h.getFixFunc())
 	if err != nil {
 		return nil, http.StatusBadRequest, fmt.Errorf("invalid query: %s", err)
 	}
 	var es []index.Entry
 	err = q.ExecuteFunc(h.index, func(e index.Entry) bool {
+		if !hasMinScore(e, data.M) || !nested(e) {
+			return true
+		}
 		if data.S > 0 {
 			data.S--
 			return true
@@ -179,6 +211,37 @@ func (h handle) get(r *http.Request) (interface{}, int, error) {
 	return es, http.StatusOK, nil
 }
 
+// hasMinScore returns false if the entry was
+// disambiguated with a score less than m.
+func hasMinScore(e index.Entry, m float64) bool {
+	return e.Resolver == "" || e.Score >= m
+}
+
+// Values of the nested parameter of get queries.
+const (
+	// NestedInclude includes nested entries (default).
+	NestedInclude = "include"
+	// NestedExclude excludes nested entries.
+	NestedExclude = "exclude"
+	// NestedOnly excludes maximal entries.
+	NestedOnly = "only"
+)
+
+// nestedFilter returns a function that returns
+// true for the entries that are selected by mode.
+func nestedFilter(mode string) (func(index.Entry) bool, error) {
+	switch strings.ToLower(mode) {
+	case "", NestedInclude:
+		return func(index.Entry) bool { return true }, nil
+	case NestedExclude:
+		return func(e index.Entry) bool { return !e.Nested }, nil
+	case NestedOnly:
+		return func(e index.Entry) bool { return e.Nested }, nil
+	default:
+		return nil, fmt.Errorf("invalid nested mode: %s", mode)
+	}
+}
+
 func (h handle) getFixFunc() query.LookupFunc {
 	return func(arg string) ([]string, error) {
 		cs := h.searcher.SearchConcepts(arg, 1)
@@ -204,17 +267,18 @@ func (h handle) ctx(r *http.Request) (interface{}, int, error) {
 	var data struct {
 		URL     string
 		B, E, N int
+		O       bool
 	}
 	if err := DecodeQuery(r.URL.Query(), &data); err != nil {
 		return nil, http.StatusBadRequest,
 			fmt.Errorf("invalid query parameters: %s", err)
 	}
-	t, err := h.readToken(data.URL)
+	t, err := h.readToken(data.URL, !data.O)
 	if err != nil {
 		return nil, http.StatusNotFound,
 			fmt.Errorf("invalid document %s: %v", data.URL, err)
 	}
-	if data.B >= len(t.Token) || data.E >= len(t.Token) {
+	if data.B >= len(t.Token) || data.E > len(t.Token) || data.B > data.E {
 		return nil, http.StatusBadRequest,
 			fmt.Errorf("invalid query paramters = %d %d", data.B, data.E)
 	}
@@ -227,13 +291,14 @@ func (h handle) ctx(r *http.Request) (interface{}, int, error) {
 		ce = len(t.Token)
 	}
 	return Context{
-		URL:    data.URL,
-		Before: t.Token[cs:data.B],
-		Match:  t.Token[data.B:data.E],
-		After:  t.Token[data.E:ce],
-		Begin:  int(data.B),
-		End:    int(data.E),
-		Len:    int(data.N),
+		URL:      data.URL,
+		Before:   t.Token[cs:data.B],
+		Match:    t.Token[data.B:data.E],
+		After:    t.Token[data.E:ce],
+		Begin:    int(data.B),
+		End:      int(data.E),
+		Len:      int(data.N),
+		Original: data.O,
 	}, http.StatusOK, nil
 }
 
@@ -258,21 +323,70 @@ func (h handle) dump(r *http.Request) (interface{}, int, error) {
 	}, http.StatusOK, nil
 }
 
-func (h handle) readToken(url string) (semix.Token, error) {
+func (h handle) evalRule(r *http.Request) (interface{}, int, error) {
+	var data struct {
+		Rule, URL string
+		Concepts  []string
+		N         int
+	}
+	if err := DecodeQuery(r.URL.Query(), &data); err != nil {
+		return nil, http.StatusBadRequest,
+			fmt.Errorf("invalid query: %s", err)
+	}
+	rl, ok := h.rules.get()[data.URL]
+	if data.Rule != "" {
+		var err error
+		if rl, err = rule.Compile(data.Rule, h.searcher.LookupID); err != nil {
+			return nil, http.StatusBadRequest,
+				fmt.Errorf("invalid rule %q: %v", data.Rule, err)
+		}
+	} else if !ok {
+		return nil, http.StatusNotFound,
+			fmt.Errorf("no rule for concept: %s", data.URL)
+	}
+	mem, err := h.searcher.NewMemory(data.N, data.Concepts)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	score, trace := rl.Trace(mem, h.graph)
+	return RuleTrace{
+		URL:   data.URL,
+		Rule:  rl.String(),
+		Score: score,
+		Trace: trace,
+	}, http.StatusOK, nil
+}
+
+func (h handle) reloadRules(r *http.Request) (interface{}, int, error) {
+	n, errs, err := h.rules.reload()
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	res := RulesReload{Rules: n, Errors: make([]RuleError, len(errs))}
+	for i, err := range errs {
+		res.Errors[i] = RuleError{URL: err.URL, Rule: err.Expr, Error: err.Err.Error()}
+	}
+	return res, http.StatusOK, nil
+}
+
+// readToken reads the document with the given url into one token.
+// If normalize is false, the token holds the original document.
+func (h handle) readToken(url string, normalize bool) (semix.Token, error) {
 	var d semix.Document
 	if strings.HasPrefix(url, "semix-") {
 		d = openDumpFile(h.dir, url)
 	} else {
 		d = semix.NewHTTPDocument(url)
 	}
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-	s := semix.Normalize(ctx, semix.Read(ctx, d))
-	t := <-s
-	if t.Err != nil {
-		return semix.Token{}, t.Err
+	t, err := semix.ReadToken(d)
+	if err != nil {
+		return semix.Token{}, err
+	}
+	if normalize {
+		t.Token = h.norm.NormalizeString(t.Token, true)
+		t.End = t.Begin + len(t.Token)
 	}
-	return t.Token, nil
+	return t, nil
 }
 
 func (h handle) lookup(data lookupData) (*semix.Concept, bool) {
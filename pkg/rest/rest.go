@@ -3,9 +3,11 @@ package rest
 import (
 	"context"
 	"net/http"
+	"sync"
+	"time"
 
 	"bitbucket.org/fflo/semix/pkg/index"
-	"bitbucket.org/fflo/semix/pkg/rule"
+	"bitbucket.org/fflo/semix/pkg/resolve"
 	"bitbucket.org/fflo/semix/pkg/searcher"
 	"bitbucket.org/fflo/semix/pkg/semix"
 	"github.com/pkg/errors"
@@ -15,27 +17,109 @@ import (
 type Server struct {
 	server *http.Server
 	handle handle
+	done   chan struct{}
+	once   sync.Once
+}
+
+type config struct {
+	ruleFiles  []string
+	reload     time.Duration
+	lenient    bool
+	model      *resolve.Model
+	costs      *semix.EditCosts
+	confusions map[string]semix.EditCosts
+}
+
+// Option defines an option for a new server.
+type Option func(*config)
+
+// WithRuleFiles sets additional rule files.
+// The rules in the files override the rules of the resource.
+func WithRuleFiles(paths ...string) Option {
+	return func(c *config) {
+		c.ruleFiles = append(c.ruleFiles, paths...)
+	}
+}
+
+// WithRuleReloadInterval sets the interval in which the rule files
+// are checked for modifications. If d <= 0, the rule files are only
+// reloaded on explicit /rules/reload requests.
+func WithRuleReloadInterval(d time.Duration) Option {
+	return func(c *config) {
+		c.reload = d
+	}
+}
+
+// WithLenientRules sets the lenient mode for loading rules.
+// In lenient mode rules that cannot be compiled are skipped with a
+// warning. Otherwise the server cannot be created if any rule is invalid.
+func WithLenientRules(lenient bool) Option {
+	return func(c *config) {
+		c.lenient = lenient
+	}
+}
+
+// WithBayesModel sets the trained model of the bayes resolver.
+func WithBayesModel(m *resolve.Model) Option {
+	return func(c *config) {
+		c.model = m
+	}
+}
+
+// WithEditCosts sets the default edit costs of the approximate matching.
+func WithEditCosts(costs semix.EditCosts) Option {
+	return func(c *config) {
+		c.costs = &costs
+	}
+}
+
+// WithConfusionTables sets the named confusion tables that
+// clients can select instead of the default edit costs.
+func WithConfusionTables(tables map[string]semix.EditCosts) Option {
+	return func(c *config) {
+		c.confusions = tables
+	}
 }
 
 // New returns a new server instance.
-func New(self, dir string, r *semix.Resource, i index.Interface) (*Server, error) {
-	searcher := searcher.New(r.Graph, r.Dictionary)
-	rules, err := rule.NewMap(r.Rules, func(str string) int {
-		cs := searcher.SearchConcepts(str, 2)
-		if len(cs) != 1 {
-			return -1
-		}
-		return int(cs[0].ID())
-	})
+func New(self, dir string, r *semix.Resource, i index.Interface, opts ...Option) (*Server, error) {
+	var cfg config
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	searcher := searcher.New(r.Graph, r.Dictionary, searcher.WithNormalizer(r.Normalizer))
+	rules, err := newRuleSet(r.Rules, cfg.ruleFiles, searcher, cfg.lenient)
 	if err != nil {
 		return nil, err
 	}
+	costs := semix.DefaultEditCosts()
+	if cfg.costs != nil {
+		costs = cfg.costs.Normalize(r.Normalizer)
+		if err := costs.Check(); err != nil {
+			return nil, err
+		}
+	}
+	confusions := make(map[string]semix.EditCosts, len(cfg.confusions))
+	for name, table := range cfg.confusions {
+		table = table.Normalize(r.Normalizer)
+		if err := table.Check(); err != nil {
+			return nil, errors.Wrapf(err, "invalid confusion table %s", name)
+		}
+		confusions[name] = table
+	}
 	h := handle{
-		dir:      dir,
-		dfa:      r.DFA,
-		searcher: searcher,
-		rules:    rules,
-		index:    i,
+		dir:        dir,
+		dfa:        r.DFA,
+		ac:         r.AhoCorasick,
+		costs:      costs,
+		confusions: confusions,
+		norm:       r.Normalizer,
+		graph:      r.Graph,
+		searcher:   searcher,
+		rules:      rules,
+		model:      cfg.model,
+		cache:      resolve.NewCache(),
+		index:      i,
 	}
 	mux := http.NewServeMux()
 	mux.HandleFunc("/concept", WithLogging(WithGet(requestFunc(h.concept))))
@@ -48,12 +132,19 @@ func New(self, dir string, r *semix.Resource, i index.Interface) (*Server, error
 	mux.HandleFunc("/info", WithLogging(WithGet(requestFunc(h.info))))
 	mux.HandleFunc("/dump", WithLogging(WithGet(requestFunc(h.dump))))
 	mux.HandleFunc("/flush", WithLogging(WithGet(requestFunc(h.flush))))
+	mux.HandleFunc("/rule/eval", WithLogging(WithGet(requestFunc(h.evalRule))))
+	mux.HandleFunc("/rules/reload", WithLogging(WithGet(requestFunc(h.reloadRules))))
+	done := make(chan struct{})
+	if cfg.reload > 0 && len(cfg.ruleFiles) > 0 {
+		go rules.watch(cfg.reload, done)
+	}
 	return &Server{
 		server: &http.Server{
 			Addr:    self,
 			Handler: mux,
 		},
 		handle: h,
+		done:   done,
 	}, nil
 }
 
@@ -64,6 +155,7 @@ func (s *Server) ListenAndServe() error {
 
 // Close closes the server and its enclosed index.
 func (s *Server) Close() error {
+	s.once.Do(func() { close(s.done) })
 	err := errors.Wrapf(s.handle.index.Close(), "cannot close index")
 	err = errors.Wrapf(s.server.Shutdown(context.TODO()), "cannot shutdown server")
 	return err
@@ -0,0 +1,158 @@
+package rest
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"bitbucket.org/fflo/semix/pkg/resource"
+	"bitbucket.org/fflo/semix/pkg/rule"
+	"bitbucket.org/fflo/semix/pkg/say"
+	"bitbucket.org/fflo/semix/pkg/searcher"
+	"github.com/pkg/errors"
+)
+
+// ruleSet holds the compiled rules of the resource and of
+// the additional rule files. It is safe for concurrent use.
+type ruleSet struct {
+	mutex    sync.RWMutex
+	rules    rule.Map
+	base     map[string]string
+	files    []string
+	mtimes   map[string]time.Time
+	searcher searcher.Searcher
+}
+
+// newRuleSet compiles the rules of the resource and the rule files.
+// All rules are linted and the findings are logged.
+// If lenient is false, any error in any rule results in an error.
+// Otherwise rules with errors are skipped.
+func newRuleSet(base map[string]string, files []string, s searcher.Searcher, lenient bool) (*ruleSet, error) {
+	set := &ruleSet{base: base, files: files, searcher: s}
+	rs, err := set.read()
+	if err != nil {
+		return nil, err
+	}
+	logFindings(rule.LintMap(rs, s.LookupIDs))
+	if !lenient {
+		rules, err := rule.NewMap(rs, s.LookupID)
+		if err != nil {
+			return nil, err
+		}
+		set.rules = rules
+		return set, nil
+	}
+	rules, errs := rule.Map(nil).Update(rs, s.LookupID)
+	for _, err := range errs {
+		say.Info("warning: skipping rule: %s", err)
+	}
+	set.rules = rules
+	return set, nil
+}
+
+// logFindings logs the lint findings of the rules.
+// The findings record their severity.
+func logFindings(fs []rule.Finding) {
+	for _, f := range fs {
+		say.Info("lint: %s", f)
+	}
+}
+
+// get returns the current rule map.
+func (s *ruleSet) get() rule.Map {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.rules
+}
+
+// reload rereads the rule files and recompiles all rules.
+// Rules that fail to compile keep their old version.
+// It returns the number of loaded rules and the compile
+// errors of the failed rules.
+func (s *ruleSet) reload() (int, []rule.Error, error) {
+	rs, err := s.read()
+	if err != nil {
+		return 0, nil, err
+	}
+	logFindings(rule.LintMap(rs, s.searcher.LookupIDs))
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	rules, errs := s.rules.Update(rs, s.searcher.LookupID)
+	s.rules = rules
+	return len(rules), errs, nil
+}
+
+// read reads the rule files, merges them with the rules
+// of the resource and records the modification times of the files.
+func (s *ruleSet) read() (map[string]string, error) {
+	mtimes, err := s.stat()
+	if err != nil {
+		return nil, err
+	}
+	rs, err := resource.MergeRules(s.base, s.files...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot read rule files")
+	}
+	s.mutex.Lock()
+	s.mtimes = mtimes
+	s.mutex.Unlock()
+	return rs, nil
+}
+
+func (s *ruleSet) stat() (map[string]time.Time, error) {
+	mtimes := make(map[string]time.Time, len(s.files))
+	for _, file := range s.files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot read rule file")
+		}
+		mtimes[file] = info.ModTime()
+	}
+	return mtimes, nil
+}
+
+// modified returns true if any of the rule files
+// has changed since it was last read.
+func (s *ruleSet) modified() bool {
+	mtimes, err := s.stat()
+	if err != nil {
+		say.Info("error: %s", err)
+		return false
+	}
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	for file, mtime := range mtimes {
+		if !mtime.Equal(s.mtimes[file]) {
+			return true
+		}
+	}
+	return false
+}
+
+// watch checks the rule files for modifications every d
+// and reloads the rules if any file has changed.
+// It returns if the done channel is closed.
+func (s *ruleSet) watch(d time.Duration, done <-chan struct{}) {
+	ticker := time.NewTicker(d)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			if !s.modified() {
+				continue
+			}
+			say.Info("reloading rules")
+			n, errs, err := s.reload()
+			if err != nil {
+				say.Info("error: %s", err)
+				continue
+			}
+			for _, err := range errs {
+				say.Info("error: %s", err)
+			}
+			say.Info("reloaded %d rules (%d errors)", n, len(errs))
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package rest
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"bitbucket.org/fflo/semix/pkg/searcher"
+	"bitbucket.org/fflo/semix/pkg/semix"
+)
+
+func testRulesSearcher() searcher.Searcher {
+	g := semix.NewGraph()
+	g.Add("a", "p", "b")
+	return searcher.New(g, semix.Dictionary{})
+}
+
+func TestRuleSetReload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "semix-rules")
+	if err != nil {
+		t.Fatalf("got error: %s", err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+	path := filepath.Join(dir, "test.rules")
+	write := func(content string) {
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("got error: %s", err)
+		}
+	}
+	write("x c(\"a\")>0\ny c(\"b\")>0\n")
+	base := map[string]string{"x": `c("b")>0`, "z": `c("a")>1`}
+	s, err := newRuleSet(base, []string{path}, testRulesSearcher(), false)
+	if err != nil {
+		t.Fatalf("got error: %s", err)
+	}
+	if got := s.get()["x"].String(); got != "PUSH 1;SC;PUSH 0.00;GT;" {
+		t.Fatalf("rule file does not override resource rule: %s", got)
+	}
+	if got := len(s.get()); got != 3 {
+		t.Fatalf("expected 3 rules; got %d", got)
+	}
+	write("x c(\"b\")>1\ny c(\"c\")>0\n")
+	n, errs, err := s.reload()
+	if err != nil {
+		t.Fatalf("got error: %s", err)
+	}
+	if n != 3 || len(errs) != 1 || errs[0].URL != "y" {
+		t.Fatalf("invalid reload: n=%d, errs=%v", n, errs)
+	}
+	if got := s.get()["x"].String(); got != "PUSH 3;SC;PUSH 1.00;GT;" {
+		t.Fatalf("rule was not updated: %s", got)
+	}
+	if got := s.get()["y"].String(); got != "PUSH 3;SC;PUSH 0.00;GT;" {
+		t.Fatalf("old rule was not kept: %s", got)
+	}
+	write("x c(\"b\"\n")
+	if _, err := newRuleSet(base, []string{path}, testRulesSearcher(), false); err == nil {
+		t.Fatalf("expected error")
+	}
+	s, err = newRuleSet(base, []string{path}, testRulesSearcher(), true)
+	if err != nil {
+		t.Fatalf("got error: %s", err)
+	}
+	if _, ok := s.get()["x"]; ok || len(s.get()) != 1 {
+		t.Fatalf("invalid rule was not skipped: %v", s.get())
+	}
+}
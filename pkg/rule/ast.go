@@ -16,13 +16,15 @@ const (
 	astBoolean
 	astPrefix
 	astInfix
+	astLogical
+	astConditional
 )
 
 type ast interface {
 	fmt.Stringer
 	typ() astType
 	check() astType
-	compile(func(string) int) Rule
+	compile(func(string) int) program
 }
 
 type prefix struct {
@@ -47,7 +49,7 @@ func (p prefix) String() string {
 	return fmt.Sprintf("(%c%s)", p.op, p.expr)
 }
 
-func (p prefix) compile(f func(string) int) Rule {
+func (p prefix) compile(f func(string) int) program {
 	rule := p.expr.compile(f)
 	if p.expr.check() == astBoolean {
 		return append(rule, instruction{opcode: opNOT})
@@ -74,7 +76,7 @@ func (s set) String() string {
 	return fmt.Sprintf("{%s}", strings.Join(strs, ","))
 }
 
-func (s set) compile(f func(string) int) Rule {
+func (s set) compile(f func(string) int) program {
 	ids := make([]int, 0, len(s))
 	for str := range s {
 		id := f(string(str))
@@ -84,7 +86,7 @@ func (s set) compile(f func(string) int) Rule {
 		ids = append(ids, id)
 	}
 	sort.Ints(ids)
-	rule := make(Rule, len(ids)+1)
+	rule := make(program, len(ids)+1)
 	for i, id := range ids {
 		rule[i] = instruction{opcode: opPushID, arg: float64(id)}
 	}
@@ -106,7 +108,7 @@ func (s str) String() string {
 	return fmt.Sprintf("%q", string(s))
 }
 
-func (s str) compile(func(string) int) Rule {
+func (s str) compile(func(string) int) program {
 	astFatalf("cannot compile %s", s)
 	panic("unreacheable")
 }
@@ -125,8 +127,8 @@ func (n num) String() string {
 	return fmt.Sprintf("%.2f", n)
 }
 
-func (n num) compile(func(string) int) Rule {
-	return Rule{instruction{opcode: opPushNUM, arg: float64(n)}}
+func (n num) compile(func(string) int) program {
+	return program{instruction{opcode: opPushNUM, arg: float64(n)}}
 }
 
 type boolean bool
@@ -143,11 +145,11 @@ func (b boolean) String() string {
 	return fmt.Sprintf("%t", b)
 }
 
-func (b boolean) compile(func(string) int) Rule {
+func (b boolean) compile(func(string) int) program {
 	if b {
-		return Rule{instruction{opcode: opPushTRUE}}
+		return program{instruction{opcode: opPushTRUE}}
 	}
-	return Rule{instruction{opcode: opPushFALSE}}
+	return program{instruction{opcode: opPushFALSE}}
 }
 
 type astError struct {
@@ -165,5 +167,5 @@ func checkTypIn(ast ast, t astType, set ...astType) {
 }
 
 func astFatalf(f string, args ...interface{}) {
-	panic(astError{msg: fmt.Sprintf(f, args)})
+	panic(astError{msg: fmt.Sprintf(f, args...)})
 }
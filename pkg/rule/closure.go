@@ -0,0 +1,329 @@
+package rule
+
+import (
+	"math"
+
+	"bitbucket.org/fflo/semix/pkg/memory"
+	"bitbucket.org/fflo/semix/pkg/semix"
+)
+
+// numFunc evaluates numerical and boolean expressions.
+// Booleans are represented as 0 (false) and 1 (true).
+type numFunc func(*memory.Memory, *semix.Graph) float64
+
+// setFunc evaluates set expressions.
+// The returned sets are sorted and must not be modified.
+type setFunc func(*memory.Memory, *semix.Graph) []float64
+
+// closureCompiler compiles checked expressions to closures.
+// Constant sub expressions are evaluated once at compile time.
+type closureCompiler struct {
+	lookup func(string) int
+}
+
+// rule compiles the root expression of a rule.
+// As the instructions of a rule, set expressions
+// evaluate to the length of the resulting set.
+func (cc closureCompiler) rule(a ast) numFunc {
+	if a.check() == astSet {
+		f := cc.set(a)
+		return func(mem *memory.Memory, g *semix.Graph) float64 {
+			return float64(len(f(mem, g)))
+		}
+	}
+	return cc.num(a)
+}
+
+func (cc closureCompiler) num(a ast) numFunc {
+	if isConst(a) {
+		v := a.compile(cc.lookup).run(nil, nil).pop1()
+		return func(*memory.Memory, *semix.Graph) float64 {
+			return v
+		}
+	}
+	switch t := a.(type) {
+	case prefix:
+		return cc.prefix(t)
+	case infix:
+		return cc.infix(t)
+	case logical:
+		return cc.logical(t)
+	case conditional:
+		then, els, cond := cc.num(t.then), cc.num(t.els), cc.num(t.cond)
+		return func(mem *memory.Memory, g *semix.Graph) float64 {
+			if cond(mem, g) != 0 {
+				return then(mem, g)
+			}
+			return els(mem, g)
+		}
+	case function:
+		return cc.function(t)
+	}
+	astFatalf("cannot compile %s", a)
+	panic("unreacheable")
+}
+
+func (cc closureCompiler) set(a ast) setFunc {
+	if isConst(a) {
+		s := a.compile(cc.lookup).run(nil, nil).popArray1()
+		return func(*memory.Memory, *semix.Graph) []float64 {
+			return s
+		}
+	}
+	switch t := a.(type) {
+	case infix:
+		l, r := cc.set(t.left), cc.set(t.right)
+		var op func(a, b []float64) []float64
+		switch t.op {
+		case plus:
+			op = arrayU
+		case mul:
+			op = arrayI
+		case minus:
+			op = arraySUB
+		default:
+			astFatalf("invalid type or operator: %s", t)
+		}
+		return func(mem *memory.Memory, g *semix.Graph) []float64 {
+			return op(l(mem, g), r(mem, g))
+		}
+	case conditional:
+		then, els, cond := cc.set(t.then), cc.set(t.els), cc.num(t.cond)
+		return func(mem *memory.Memory, g *semix.Graph) []float64 {
+			if cond(mem, g) != 0 {
+				return then(mem, g)
+			}
+			return els(mem, g)
+		}
+	case function:
+		switch t.name {
+		case "e":
+			return func(mem *memory.Memory, _ *semix.Graph) []float64 {
+				return elems(mem.Elements())
+			}
+		case "es":
+			return func(mem *memory.Memory, _ *semix.Graph) []float64 {
+				return elems(mem.ElementsS())
+			}
+		case "c":
+			ids := cc.set(t.args[0])(nil, nil)
+			return func(mem *memory.Memory, _ *semix.Graph) []float64 {
+				return countC(mem, ids)
+			}
+		case "cs":
+			ids := cc.set(t.args[0])(nil, nil)
+			return func(mem *memory.Memory, _ *semix.Graph) []float64 {
+				return countCS(mem, ids)
+			}
+		}
+	}
+	astFatalf("cannot compile %s", a)
+	panic("unreacheable")
+}
+
+func (cc closureCompiler) prefix(p prefix) numFunc {
+	f := cc.num(p.expr)
+	if p.expr.check() == astBoolean {
+		return func(mem *memory.Memory, g *semix.Graph) float64 {
+			return boolean2float(f(mem, g) == 0)
+		}
+	}
+	return func(mem *memory.Memory, g *semix.Graph) float64 {
+		return -f(mem, g)
+	}
+}
+
+func (cc closureCompiler) infix(i infix) numFunc {
+	if i.left.check() == astSet {
+		if i.op != eq {
+			astFatalf("invalid type or operator: %s", i)
+		}
+		l, r := cc.set(i.left), cc.set(i.right)
+		return func(mem *memory.Memory, g *semix.Graph) float64 {
+			return boolean2float(arrayEQ(l(mem, g), r(mem, g)))
+		}
+	}
+	l, r := cc.num(i.left), cc.num(i.right)
+	boolean := i.left.check() == astBoolean
+	switch {
+	case i.op == eq:
+		return func(mem *memory.Memory, g *semix.Graph) float64 {
+			return boolean2float(l(mem, g) == r(mem, g))
+		}
+	case i.op == lt && !boolean:
+		return func(mem *memory.Memory, g *semix.Graph) float64 {
+			return boolean2float(l(mem, g) < r(mem, g))
+		}
+	case i.op == gt && !boolean:
+		return func(mem *memory.Memory, g *semix.Graph) float64 {
+			return boolean2float(l(mem, g) > r(mem, g))
+		}
+	case i.op == plus && boolean:
+		return func(mem *memory.Memory, g *semix.Graph) float64 {
+			return boolean2float(l(mem, g) != 0 || r(mem, g) != 0)
+		}
+	case i.op == mul && boolean:
+		return func(mem *memory.Memory, g *semix.Graph) float64 {
+			return boolean2float(l(mem, g) != 0 && r(mem, g) != 0)
+		}
+	case i.op == plus:
+		return func(mem *memory.Memory, g *semix.Graph) float64 {
+			return l(mem, g) + r(mem, g)
+		}
+	case i.op == minus && !boolean:
+		return func(mem *memory.Memory, g *semix.Graph) float64 {
+			return l(mem, g) - r(mem, g)
+		}
+	case i.op == mul:
+		return func(mem *memory.Memory, g *semix.Graph) float64 {
+			return l(mem, g) * r(mem, g)
+		}
+	case i.op == div && !boolean:
+		return func(mem *memory.Memory, g *semix.Graph) float64 {
+			return l(mem, g) / r(mem, g)
+		}
+	}
+	astFatalf("invalid type or operator: %s", i)
+	panic("unreacheable")
+}
+
+func (cc closureCompiler) logical(l logical) numFunc {
+	left, right := cc.num(l.left), cc.num(l.right)
+	switch l.op {
+	case land:
+		return func(mem *memory.Memory, g *semix.Graph) float64 {
+			if left(mem, g) == 0 {
+				return 0
+			}
+			return right(mem, g)
+		}
+	case lor:
+		return func(mem *memory.Memory, g *semix.Graph) float64 {
+			if left(mem, g) != 0 {
+				return 1
+			}
+			return right(mem, g)
+		}
+	}
+	astFatalf("invalid expression: %s", l)
+	panic("unreacheable")
+}
+
+func (cc closureCompiler) function(f function) numFunc {
+	switch f.name {
+	case "len":
+		if len(f.args) == 0 {
+			return func(mem *memory.Memory, _ *semix.Graph) float64 {
+				return float64(mem.Len())
+			}
+		}
+		s := cc.set(f.args[0])
+		return func(mem *memory.Memory, g *semix.Graph) float64 {
+			return float64(len(s(mem, g)))
+		}
+	case "n":
+		return func(mem *memory.Memory, _ *semix.Graph) float64 {
+			return float64(mem.N())
+		}
+	case "c":
+		pred := equalsID(int(mustFindID(f.args[0], cc.lookup)))
+		return func(mem *memory.Memory, _ *semix.Graph) float64 {
+			return float64(mem.CountIf(pred))
+		}
+	case "cs":
+		pred := equalsID(int(mustFindID(f.args[0], cc.lookup)))
+		return func(mem *memory.Memory, _ *semix.Graph) float64 {
+			return float64(mem.CountIfS(pred))
+		}
+	case "w":
+		pred := equalsID(int(mustFindID(f.args[0], cc.lookup)))
+		return func(mem *memory.Memory, _ *semix.Graph) float64 {
+			return mem.CountIfW(pred)
+		}
+	case "ws":
+		pred := equalsID(int(mustFindID(f.args[0], cc.lookup)))
+		return func(mem *memory.Memory, _ *semix.Graph) float64 {
+			return mem.CountIfSW(pred)
+		}
+	case "min":
+		return cc.minMax(f, arrayMIN, math.Min)
+	case "max":
+		return cc.minMax(f, arrayMAX, math.Max)
+	case "log":
+		a := cc.num(f.args[0])
+		return func(mem *memory.Memory, g *semix.Graph) float64 {
+			return math.Log(a(mem, g))
+		}
+	case "exp":
+		a := cc.num(f.args[0])
+		return func(mem *memory.Memory, g *semix.Graph) float64 {
+			return math.Exp(a(mem, g))
+		}
+	case "pow":
+		a, b := cc.num(f.args[0]), cc.num(f.args[1])
+		return func(mem *memory.Memory, g *semix.Graph) float64 {
+			return math.Pow(a(mem, g), b(mem, g))
+		}
+	case "isa", "under":
+		class, pred := cc.classAndPredicate(f)
+		if f.name == "isa" {
+			return func(mem *memory.Memory, _ *semix.Graph) float64 {
+				return boolean2float(countUnder(mem, class, pred) > 0)
+			}
+		}
+		return func(mem *memory.Memory, _ *semix.Graph) float64 {
+			return float64(countUnder(mem, class, pred))
+		}
+	case "dist":
+		a := int(mustFindID(f.args[0], cc.lookup))
+		if len(f.args) == 1 {
+			return func(mem *memory.Memory, g *semix.Graph) float64 {
+				return memDistance(mem, g, a)
+			}
+		}
+		b := int(mustFindID(f.args[1], cc.lookup))
+		return func(_ *memory.Memory, g *semix.Graph) float64 {
+			return graphDistance(g, a, b)
+		}
+	}
+	astFatalf("cannot compile %s: invalid type or instruction", f)
+	panic("unreacheable")
+}
+
+// minMax compiles min and max either for one set argument or
+// for a list of numerical arguments.
+func (cc closureCompiler) minMax(f function, array func([]float64) float64,
+	combine func(float64, float64) float64) numFunc {
+	if len(f.args) == 1 && f.args[0].check() == astSet {
+		s := cc.set(f.args[0])
+		return func(mem *memory.Memory, g *semix.Graph) float64 {
+			return array(s(mem, g))
+		}
+	}
+	args := make([]numFunc, len(f.args))
+	for i, arg := range f.args {
+		args[i] = cc.num(arg)
+	}
+	return func(mem *memory.Memory, g *semix.Graph) float64 {
+		res := args[0](mem, g)
+		for _, arg := range args[1:] {
+			res = combine(res, arg(mem, g))
+		}
+		return res
+	}
+}
+
+func (cc closureCompiler) classAndPredicate(f function) (int, int) {
+	class := int(mustFindID(f.args[0], cc.lookup))
+	if len(f.args) == 1 {
+		return class, 0
+	}
+	return class, int(mustFindID(f.args[1], cc.lookup))
+}
+
+func boolean2float(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -39,6 +39,10 @@ func (f function) check() astType {
 		return f.countsCheck()
 	case "cs":
 		return f.countsCheck()
+	case "w":
+		return f.weightCheck()
+	case "ws":
+		return f.weightCheck()
 	case "log":
 		return f.numCheck(1)
 	case "exp":
@@ -47,35 +51,51 @@ func (f function) check() astType {
 		return f.numCheck(2)
 	case "n":
 		return f.numCheck(0)
+	case "isa":
+		return f.graphCheck(astBoolean)
+	case "under":
+		return f.graphCheck(astNum)
+	case "dist":
+		return f.graphCheck(astNum)
 	}
 	astFatalf("invalid function name: %s", f)
 	panic("unreacheable")
 }
 
-func (f function) compile(l func(string) int) Rule {
+func (f function) compile(l func(string) int) program {
 	switch f.name {
 	case "len":
 		if len(f.args) == 0 {
-			return Rule{instruction{opcode: opMemLEN}}
+			return program{instruction{opcode: opMemLEN}}
 		}
 		switch f.args[0].check() {
 		case astStr:
-			return Rule{
+			return program{
 				instruction{opcode: opPushID, arg: float64(len(f.args[0].(str)))},
 			}
 		case astSet:
 			return append(f.combine(l, f.args[0]), instruction{opcode: opLEN})
 		}
 	case "e":
-		return Rule{instruction{opcode: opE}}
+		return program{instruction{opcode: opE}}
 	case "es":
-		return Rule{instruction{opcode: opES}}
+		return program{instruction{opcode: opES}}
 	case "c":
 		return f.compileCount(l, false)
 	case "cs":
 		return f.compileCount(l, true)
+	case "w":
+		return program{
+			instruction{opcode: opPushID, arg: mustFindID(f.args[0], l)},
+			instruction{opcode: opW},
+		}
+	case "ws":
+		return program{
+			instruction{opcode: opPushID, arg: mustFindID(f.args[0], l)},
+			instruction{opcode: opWS},
+		}
 	case "n":
-		return Rule{instruction{opcode: opMemN}}
+		return program{instruction{opcode: opMemN}}
 	case "max":
 		return append(f.minMaxCombine(l), instruction{opcode: opMAX})
 	case "min":
@@ -86,8 +106,14 @@ func (f function) compile(l func(string) int) Rule {
 		return append(f.combine(l, f.args...), instruction{opcode: opEXP})
 	case "pow":
 		return append(f.combine(l, f.args...), instruction{opcode: opPOW})
+	case "isa":
+		return append(f.compileUnder(l), instruction{opcode: opISA})
+	case "under":
+		return append(f.compileUnder(l), instruction{opcode: opUNDER})
+	case "dist":
+		return f.compileDist(l)
 	}
-	astFatalf("cannot compile %s: invalid type or instruction")
+	astFatalf("cannot compile %s: invalid type or instruction", f)
 	panic("unreacheable")
 }
 
@@ -114,15 +140,15 @@ func (f function) countsCheck() astType {
 	panic("unreacheable")
 }
 
-func (f function) compileCount(g func(string) int, star bool) Rule {
+func (f function) compileCount(g func(string) int, star bool) program {
 	switch f.args[0].check() {
 	case astStr:
-		return Rule{
+		return program{
 			instruction{opcode: opPushID, arg: mustFindID(f.args[0], g)},
 			instruction{opcode: countOpcode(true, star)},
 		}
 	case astSet:
-		var rule Rule
+		var rule program
 		for _, id := range mustFindIDs(f.args[0], g) {
 			rule = append(rule, instruction{opcode: opPushID, arg: id})
 		}
@@ -164,6 +190,15 @@ func mustFindID(ast ast, f func(string) int) float64 {
 	return float64(id)
 }
 
+// w/ws expect exactly one concept name
+// and return its weighted count.
+func (f function) weightCheck() astType {
+	if len(f.args) != 1 || f.args[0].check() != astStr {
+		astFatalf("invalid arguments: %s", f)
+	}
+	return astNum
+}
+
 func (f function) lenCheck() astType {
 	if len(f.args) == 0 {
 		return astNum
@@ -203,11 +238,45 @@ func (f function) numCheck(n int) astType {
 	return astNum
 }
 
-func (f function) minMaxCombine(g func(string) int) Rule {
+// isa, under and dist expect one or two concept names.
+func (f function) graphCheck(t astType) astType {
+	if len(f.args) != 1 && len(f.args) != 2 {
+		astFatalf("invalid arguments: %s", f)
+	}
+	for _, arg := range f.args {
+		if arg.check() != astStr {
+			astFatalf("invalid arguments: %s", f)
+		}
+	}
+	return t
+}
+
+// compileUnder pushes the id of the class and the id
+// of the optional predicate (or 0) onto the stack.
+func (f function) compileUnder(g func(string) int) program {
+	rule := program{instruction{opcode: opPushID, arg: mustFindID(f.args[0], g)}}
+	if len(f.args) == 1 {
+		return append(rule, instruction{opcode: opPushID, arg: 0})
+	}
+	return append(rule, instruction{opcode: opPushID, arg: mustFindID(f.args[1], g)})
+}
+
+func (f function) compileDist(g func(string) int) program {
+	rule := program{instruction{opcode: opPushID, arg: mustFindID(f.args[0], g)}}
+	if len(f.args) == 1 {
+		return append(rule, instruction{opcode: opMemDIST})
+	}
+	return append(rule,
+		instruction{opcode: opPushID, arg: mustFindID(f.args[1], g)},
+		instruction{opcode: opDIST},
+	)
+}
+
+func (f function) minMaxCombine(g func(string) int) program {
 	if len(f.args) == 0 {
-		return Rule{instruction{opcode: opPushID, arg: 0}}
+		return program{instruction{opcode: opPushID, arg: 0}}
 	}
-	var rule Rule
+	var rule program
 	var n int
 	for _, arg := range f.args {
 		if arg.check() == astSet {
@@ -220,8 +289,8 @@ func (f function) minMaxCombine(g func(string) int) Rule {
 	return rule
 }
 
-func (f function) combine(g func(string) int, args ...ast) Rule {
-	var rule Rule
+func (f function) combine(g func(string) int, args ...ast) program {
+	var rule program
 	for _, arg := range args {
 		rule = append(rule, arg.compile(g)...)
 	}
@@ -0,0 +1,117 @@
+package rule
+
+import (
+	"math"
+
+	"bitbucket.org/fflo/semix/pkg/memory"
+	"bitbucket.org/fflo/semix/pkg/semix"
+)
+
+// countUnder returns the number of concepts in the memory that are
+// descendants of the class with the given id (or the class itself).
+// If pred is not 0, only edges with the predicate pred are followed.
+func countUnder(mem *memory.Memory, class, pred int) int {
+	return mem.CountIf(func(c *semix.Concept) bool {
+		return distance(c, class, pred) >= 0
+	})
+}
+
+// memDistance returns the minimal distance (see graphDistance)
+// of any concept in the memory to the concept with the given id.
+// If no such path exists or if the graph is nil,
+// math.MaxFloat64 is returned.
+func memDistance(mem *memory.Memory, g *semix.Graph, id int) float64 {
+	if g == nil {
+		return math.MaxFloat64
+	}
+	c, ok := g.FindByID(int32(id))
+	if !ok {
+		return math.MaxFloat64
+	}
+	ds := distances(c)
+	min := math.MaxFloat64
+	mem.Each(func(c *semix.Concept) {
+		if d := commonDistance(distances(c), ds); d < min {
+			min = d
+		}
+	})
+	return min
+}
+
+// graphDistance returns the length of the shortest path between
+// the concepts with the ids a and b over a common ancestor, i.e.
+// a concept that can be reached from both concepts following the
+// concepts' edges. So the distance of a concept to its parent is 1
+// and the distance of two siblings is 2. If no such path exists or
+// if the graph is nil, math.MaxFloat64 is returned.
+func graphDistance(g *semix.Graph, a, b int) float64 {
+	if g == nil {
+		return math.MaxFloat64
+	}
+	ca, oka := g.FindByID(int32(a))
+	cb, okb := g.FindByID(int32(b))
+	if !oka || !okb {
+		return math.MaxFloat64
+	}
+	return commonDistance(distances(ca), distances(cb))
+}
+
+// commonDistance returns the minimal sum of the distances
+// of the common ancestors or math.MaxFloat64 if there is none.
+func commonDistance(a, b map[int]int) float64 {
+	min := math.MaxFloat64
+	for id, da := range a {
+		if db, ok := b[id]; ok && float64(da+db) < min {
+			min = float64(da + db)
+		}
+	}
+	return min
+}
+
+// distances returns the lengths of the shortest paths from the given
+// concept to all concepts (by their ids) that can be reached following
+// the concepts' edges, including the concept itself.
+func distances(c *semix.Concept) map[int]int {
+	ds := map[int]int{absID(c.ID()): 0}
+	queue := []*semix.Concept{c}
+	for d := 1; len(queue) > 0; d++ {
+		var next []*semix.Concept
+		for _, c := range queue {
+			c.EachEdge(func(e semix.Edge) {
+				if _, ok := ds[absID(e.O.ID())]; ok {
+					return
+				}
+				ds[absID(e.O.ID())] = d
+				next = append(next, e.O)
+			})
+		}
+		queue = next
+	}
+	return ds
+}
+
+// distance returns the length of the shortest path from the given
+// concept to the concept with the given id following the concepts' edges.
+// If pred is not 0, only edges with the predicate pred are followed.
+// If no such path exists, -1 is returned.
+func distance(c *semix.Concept, id, pred int) int {
+	visited := map[*semix.Concept]bool{c: true}
+	queue := []*semix.Concept{c}
+	for d := 0; len(queue) > 0; d++ {
+		var next []*semix.Concept
+		for _, c := range queue {
+			if absID(c.ID()) == id {
+				return d
+			}
+			c.EachEdge(func(e semix.Edge) {
+				if visited[e.O] || (pred != 0 && absID(e.P.ID()) != pred) {
+					return
+				}
+				visited[e.O] = true
+				next = append(next, e.O)
+			})
+		}
+		queue = next
+	}
+	return -1
+}
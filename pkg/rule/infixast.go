@@ -46,7 +46,7 @@ func (i infix) check() astType {
 	panic("unreacheable")
 }
 
-func (i infix) compile(f func(string) int) Rule {
+func (i infix) compile(f func(string) int) program {
 	switch i.left.check() {
 	case astBoolean:
 		switch i.op {
@@ -88,18 +88,18 @@ func (i infix) compile(f func(string) int) Rule {
 	case astStr:
 		switch i.op {
 		case '=':
-			return Rule{booleanInstruction(i.left.(str) == i.right.(str))}
+			return program{booleanInstruction(i.left.(str) == i.right.(str))}
 		case '<':
-			return Rule{booleanInstruction(i.left.(str) < i.right.(str))}
+			return program{booleanInstruction(i.left.(str) < i.right.(str))}
 		case '>':
-			return Rule{booleanInstruction(i.left.(str) > i.right.(str))}
+			return program{booleanInstruction(i.left.(str) > i.right.(str))}
 		}
 	}
 	astFatalf("invalid type or operator: %s", i)
 	panic("unreacheable")
 }
 
-func (i infix) combine(f func(string) int, instr instruction) Rule {
+func (i infix) combine(f func(string) int, instr instruction) program {
 	rule := i.left.compile(f)
 	rule = append(rule, i.right.compile(f)...)
 	return append(rule, instr)
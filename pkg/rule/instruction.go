@@ -45,6 +45,14 @@ const (
 	opES
 	opMemN
 	opMemLEN
+	opJMP
+	opJF
+	opISA
+	opUNDER
+	opDIST
+	opMemDIST
+	opW
+	opWS
 )
 
 type instruction struct {
@@ -59,7 +67,9 @@ func booleanInstruction(b bool) instruction {
 	return instruction{opcode: opPushFALSE}
 }
 
-func (i instruction) call(mem *memory.Memory, stack *stack) {
+// call executes the instruction and returns the relative
+// offset of the next instruction that should be executed.
+func (i instruction) call(mem *memory.Memory, g *semix.Graph, stack *stack) int {
 	switch i.opcode {
 	case opPushNUM:
 		stack.push(i.arg)
@@ -150,9 +160,34 @@ func (i instruction) call(mem *memory.Memory, stack *stack) {
 		stack.push(float64(mem.N()))
 	case opMemLEN:
 		stack.push(float64(mem.Len()))
+	case opISA:
+		class, pred := stack.pop2()
+		stack.pushBool(countUnder(mem, int(class), int(pred)) > 0)
+	case opUNDER:
+		class, pred := stack.pop2()
+		stack.push(float64(countUnder(mem, int(class), int(pred))))
+	case opDIST:
+		a, b := stack.pop2()
+		stack.push(graphDistance(g, int(a), int(b)))
+	case opMemDIST:
+		a := stack.pop1()
+		stack.push(memDistance(mem, g, int(a)))
+	case opW:
+		a := stack.pop1()
+		stack.push(mem.CountIfW(equalsID(int(a))))
+	case opWS:
+		a := stack.pop1()
+		stack.push(mem.CountIfSW(equalsID(int(a))))
+	case opJMP:
+		return int(i.arg)
+	case opJF:
+		if !stack.popBool1() {
+			return int(i.arg)
+		}
 	default:
 		panic("invalid opcode")
 	}
+	return 1
 }
 
 func arrayEQ(a, b []float64) bool {
@@ -233,6 +268,22 @@ func (i instruction) String() string {
 		return "MN"
 	case opMemLEN:
 		return "MLEN"
+	case opISA:
+		return "ISA"
+	case opUNDER:
+		return "UNDER"
+	case opDIST:
+		return "DIST"
+	case opMemDIST:
+		return "MDIST"
+	case opW:
+		return "W"
+	case opWS:
+		return "WS"
+	case opJMP:
+		return fmt.Sprintf("JMP %d", int(i.arg))
+	case opJF:
+		return fmt.Sprintf("JF %d", int(i.arg))
 	}
 	panic("invalid opcode")
 }
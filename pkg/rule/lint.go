@@ -0,0 +1,297 @@
+package rule
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"bitbucket.org/fflo/semix/pkg/memory"
+)
+
+// Severity defines the severity of a lint finding.
+type Severity string
+
+const (
+	// SeverityWarning marks findings of rules that compile,
+	// but are most likely not what was intended.
+	SeverityWarning Severity = "warning"
+	// SeverityError marks findings of rules that cannot be compiled.
+	SeverityError Severity = "error"
+)
+
+// Finding represents a problem that was found by Lint.
+// URL is the URL of the concept of the rule.
+type Finding struct {
+	URL, Expr, Msg string
+	Severity       Severity
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s: %s: %s: %q", f.URL, f.Severity, f.Msg, f.Expr)
+}
+
+// LintMap lints all rules of the given map of rules.
+// The findings are sorted by the concepts' URLs.
+func LintMap(rs map[string]string, lookup func(string) []int) []Finding {
+	var fs []Finding
+	for url, expr := range rs {
+		fs = append(fs, Lint(url, expr, lookup)...)
+	}
+	sort.SliceStable(fs, func(i, j int) bool {
+		return fs[i].URL < fs[j].URL
+	})
+	return fs
+}
+
+// Lint checks the rule of the concept with the given URL.
+// The lookup function must return the ids of all concepts that
+// match a string. Lint reports syntax and type errors,
+// unresolved and ambiguous concept references, constant rules,
+// constant conditions with unreachable branches and comparisons
+// that are always true or false.
+func Lint(url, expr string, lookup func(string) []int) []Finding {
+	l := linter{url: url, expr: expr}
+	root, err := newParser(strings.NewReader(expr)).parse()
+	if err != nil {
+		l.errorf("syntax error: %v", err)
+		return l.fs
+	}
+	if err := checkAST(root); err != nil {
+		l.errorf("type error: %v", err)
+		return l.fs
+	}
+	if t := root.check(); t != astNum && t != astBoolean {
+		l.errorf("type error: rule must return a number or a boolean: %s", root)
+		return l.fs
+	}
+	walk(root, func(a ast) {
+		for _, ref := range references(a) {
+			switch ids := lookup(string(ref)); {
+			case len(ids) == 0:
+				l.errorf("cannot find concept: %s", ref)
+			case len(ids) > 1:
+				l.errorf("ambiguous concept: %s (%d matches)", ref, len(ids))
+			}
+		}
+	})
+	if isConst(root) {
+		l.warnf("constant rule: always %s", constString(root))
+		return l.fs
+	}
+	walk(root, l.lint)
+	return l.fs
+}
+
+type linter struct {
+	url, expr string
+	fs        []Finding
+}
+
+func (l *linter) errorf(f string, args ...interface{}) {
+	l.add(SeverityError, f, args...)
+}
+
+func (l *linter) warnf(f string, args ...interface{}) {
+	l.add(SeverityWarning, f, args...)
+}
+
+func (l *linter) add(s Severity, f string, args ...interface{}) {
+	l.fs = append(l.fs, Finding{
+		URL:      l.url,
+		Expr:     l.expr,
+		Msg:      fmt.Sprintf(f, args...),
+		Severity: s,
+	})
+}
+
+func (l *linter) lint(a ast) {
+	switch t := a.(type) {
+	case logical:
+		if !isConst(t.left) {
+			return
+		}
+		if b := constValue(t.left) != 0; (t.op == land && !b) || (t.op == lor && b) {
+			l.warnf("constant condition %s: unreachable expression: %s", t.left, t.right)
+		} else {
+			l.warnf("constant condition %s: redundant in %s", t.left, t)
+		}
+	case conditional:
+		if !isConst(t.cond) {
+			return
+		}
+		unreachable := t.els
+		if constValue(t.cond) == 0 {
+			unreachable = t.then
+		}
+		l.warnf("constant condition %s: unreachable expression: %s", t.cond, unreachable)
+	case infix:
+		if t.op != eq && t.op != lt && t.op != gt {
+			return
+		}
+		if isConst(t) {
+			l.warnf("constant comparison %s: always %s", t, constString(t))
+			return
+		}
+		if b, ok := compareNonNegative(t); ok {
+			l.warnf("comparison %s: always %t", t, b)
+		}
+	}
+}
+
+// compareNonNegative checks if the comparison of a non negative
+// function with a constant is always true or always false.
+func compareNonNegative(i infix) (bool, bool) {
+	op, f, c := i.op, i.left, i.right
+	if isConst(f) {
+		f, c = c, f
+		switch op {
+		case lt:
+			op = gt
+		case gt:
+			op = lt
+		}
+	}
+	if !isNonNegative(f) || !isConst(c) {
+		return false, false
+	}
+	v := constValue(c)
+	switch {
+	case op == lt && v <= 0:
+		return false, true
+	case op == gt && v < 0:
+		return true, true
+	case op == eq && v < 0:
+		return false, true
+	}
+	return false, false
+}
+
+// isNonNegative returns true if the expression is a numeric function
+// that never returns negative values.
+func isNonNegative(a ast) bool {
+	f, ok := a.(function)
+	if !ok || f.check() != astNum {
+		return false
+	}
+	switch f.name {
+	case "c", "cs", "w", "ws", "n", "len", "under", "dist":
+		return true
+	}
+	return false
+}
+
+// isConst returns true if the expression does not depend
+// on the memory or the graph.
+func isConst(a ast) bool {
+	switch t := a.(type) {
+	case prefix:
+		return isConst(t.expr)
+	case infix:
+		return isConst(t.left) && isConst(t.right)
+	case logical:
+		return isConst(t.left) && isConst(t.right)
+	case conditional:
+		return isConst(t.cond) && isConst(t.then) && isConst(t.els)
+	case function:
+		switch t.name {
+		case "min", "max", "log", "exp", "pow":
+		case "len":
+			if len(t.args) == 0 {
+				return false
+			}
+		default:
+			return false
+		}
+		for _, arg := range t.args {
+			if !isConst(arg) {
+				return false
+			}
+		}
+		return true
+	}
+	return true
+}
+
+// constValue evaluates a constant expression.
+// Since the value of constant expressions
+// does not depend on the ids of the concepts, an
+// arbitrary lookup function and an empty memory is used.
+func constValue(a ast) float64 {
+	ids := make(map[string]int)
+	r := a.compile(func(str string) int {
+		if _, ok := ids[str]; !ok {
+			ids[str] = len(ids) + 1
+		}
+		return ids[str]
+	})
+	return r.run(memory.New(1), nil).pop1()
+}
+
+func constString(a ast) string {
+	v := constValue(a)
+	if a.check() == astBoolean {
+		return fmt.Sprintf("%t", v != 0)
+	}
+	return fmt.Sprintf("%g", v)
+}
+
+// references returns the concept references of an expression.
+func references(a ast) []str {
+	var refs []str
+	switch t := a.(type) {
+	case set:
+		for str := range t {
+			refs = append(refs, str)
+		}
+		sort.Slice(refs, func(i, j int) bool { return refs[i] < refs[j] })
+	case function:
+		switch t.name {
+		case "c", "cs", "w", "ws", "isa", "under", "dist":
+			for _, arg := range t.args {
+				if s, ok := arg.(str); ok {
+					refs = append(refs, s)
+				}
+			}
+		}
+	}
+	return refs
+}
+
+// walk calls f for the expression and all its sub expressions.
+func walk(a ast, f func(ast)) {
+	f(a)
+	switch t := a.(type) {
+	case prefix:
+		walk(t.expr, f)
+	case infix:
+		walk(t.left, f)
+		walk(t.right, f)
+	case logical:
+		walk(t.left, f)
+		walk(t.right, f)
+	case conditional:
+		walk(t.cond, f)
+		walk(t.then, f)
+		walk(t.els, f)
+	case function:
+		for _, arg := range t.args {
+			walk(arg, f)
+		}
+	}
+}
+
+// checkAST type checks an expression and returns
+// the check error as an error.
+func checkAST(a ast) (err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			if t, ok := e.(astError); ok {
+				err = fmt.Errorf("%s", t.msg)
+				return
+			}
+			panic(e)
+		}
+	}()
+	a.check()
+	return nil
+}
@@ -0,0 +1,78 @@
+package rule
+
+import (
+	"strings"
+	"testing"
+)
+
+func testLookupIDs(str string) []int {
+	switch str {
+	case "a":
+		return []int{1}
+	case "b":
+		return []int{2}
+	case "c":
+		return []int{3}
+	case "ab":
+		return []int{1, 2}
+	}
+	return nil
+}
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		test, want string
+		severity   Severity
+	}{
+		{`c("a")>0`, "", ""},
+		{`c("a")>0&&(isa("b")||dist("c")<2)`, "", ""},
+		{`c("a"`, "syntax error", SeverityError},
+		{`c("a")+true`, "type error", SeverityError},
+		{`e()`, "type error", SeverityError},
+		{`c("x")>0`, "cannot find concept", SeverityError},
+		{`c({"a","x"})={}`, "cannot find concept", SeverityError},
+		{`isa("ab")`, "ambiguous concept", SeverityError},
+		{`1<2`, "constant rule: always true", SeverityWarning},
+		{`max(1,3)*2`, "constant rule: always 6", SeverityWarning},
+		{`false&&c("a")>0`, "unreachable expression", SeverityWarning},
+		{`true||c("a")>0`, "unreachable expression", SeverityWarning},
+		{`true&&c("a")>0`, "redundant", SeverityWarning},
+		{`false?c("a"):c("b")`, "unreachable expression: c(\"a\")", SeverityWarning},
+		{`c("a")>0&&2<1`, "constant comparison", SeverityWarning},
+		{`c("a")<0`, "always false", SeverityWarning},
+		{`0>n()`, "always false", SeverityWarning},
+		{`c("a")>-1`, "always true", SeverityWarning},
+		{`dist("a")=-1`, "always false", SeverityWarning},
+	}
+	for _, tc := range tests {
+		t.Run(tc.test, func(t *testing.T) {
+			fs := Lint("url", tc.test, testLookupIDs)
+			if tc.want == "" {
+				if len(fs) != 0 {
+					t.Fatalf("expected no findings; got %v", fs)
+				}
+				return
+			}
+			if len(fs) != 1 {
+				t.Fatalf("expected one finding; got %v", fs)
+			}
+			if fs[0].URL != "url" || fs[0].Expr != tc.test {
+				t.Fatalf("invalid finding: %v", fs[0])
+			}
+			if fs[0].Severity != tc.severity || !strings.Contains(fs[0].Msg, tc.want) {
+				t.Fatalf("expected %s: %s; got %v", tc.severity, tc.want, fs[0])
+			}
+		})
+	}
+}
+
+func TestLintMap(t *testing.T) {
+	fs := LintMap(map[string]string{
+		"z": `c("x")>0`,
+		"y": `c("a")>0`,
+		"x": `1<2`,
+	}, testLookupIDs)
+	if len(fs) != 2 || fs[0].URL != "x" || fs[1].URL != "z" {
+		t.Fatalf("invalid findings: %v", fs)
+	}
+}
@@ -0,0 +1,90 @@
+package rule
+
+import (
+	"fmt"
+)
+
+// logical represents the short circuiting logical operators && and ||.
+type logical struct {
+	op          operator
+	left, right ast
+}
+
+func (logical) typ() astType {
+	return astLogical
+}
+
+func (l logical) check() astType {
+	checkTypIn(l, l.left.check(), astBoolean)
+	checkTypIn(l, l.right.check(), astBoolean)
+	if l.op != land && l.op != lor {
+		astFatalf("invalid expression: %s", l)
+	}
+	return astBoolean
+}
+
+// compile compiles the logical expression using conditional jumps.
+// a&&b: a;JF n+2;b;JMP 2;PUSH false;
+// a||b: a;JF 3;PUSH true;JMP n+1;b;
+func (l logical) compile(f func(string) int) program {
+	left := l.left.compile(f)
+	right := l.right.compile(f)
+	n := float64(len(right))
+	switch l.op {
+	case land:
+		rule := append(left, instruction{opcode: opJF, arg: n + 2})
+		rule = append(rule, right...)
+		return append(rule,
+			instruction{opcode: opJMP, arg: 2},
+			instruction{opcode: opPushFALSE},
+		)
+	case lor:
+		rule := append(left,
+			instruction{opcode: opJF, arg: 3},
+			instruction{opcode: opPushTRUE},
+			instruction{opcode: opJMP, arg: n + 1},
+		)
+		return append(rule, right...)
+	}
+	astFatalf("invalid expression: %s", l)
+	panic("unreacheable")
+}
+
+func (l logical) String() string {
+	return fmt.Sprintf("(%s%c%c%s)", l.left, l.op, l.op, l.right)
+}
+
+// conditional represents the conditional expression c?a:b.
+type conditional struct {
+	cond, then, els ast
+}
+
+func (conditional) typ() astType {
+	return astConditional
+}
+
+func (c conditional) check() astType {
+	checkTypIn(c, c.cond.check(), astBoolean)
+	then := c.then.check()
+	if then != c.els.check() {
+		astFatalf("invalid expression: %s", c)
+	}
+	checkTypIn(c, then, astBoolean, astNum, astSet)
+	return then
+}
+
+// compile compiles the conditional expression using conditional jumps.
+// c?a:b: c;JF n+2;a;JMP m+1;b;
+func (c conditional) compile(f func(string) int) program {
+	then := c.then.compile(f)
+	els := c.els.compile(f)
+	rule := append(c.cond.compile(f),
+		instruction{opcode: opJF, arg: float64(len(then) + 2)})
+	rule = append(rule, then...)
+	rule = append(rule, instruction{opcode: opJMP, arg: float64(len(els) + 1)})
+	return append(rule, els...)
+}
+
+func (c conditional) String() string {
+	return fmt.Sprintf("(%s?%s:%s)", c.cond, c.then, c.els)
+}
@@ -2,6 +2,9 @@ package rule
 
 const (
 	lowest  = iota + 1
+	cond    // ?:
+	or      // ||
+	and     // &&
 	equals  // =
 	compare // <, >
 	line    // +, -
@@ -20,10 +23,19 @@ const (
 	mul   operator = '*'
 	plus  operator = '+'
 	minus operator = '-'
+	lor   operator = '|'
+	land  operator = '&'
+	ask   operator = '?'
 )
 
 func precedence(tok rune) int {
 	switch tok {
+	case '?':
+		return cond
+	case '|':
+		return or
+	case '&':
+		return and
 	case '=':
 		return equals
 	case '>':
@@ -55,6 +55,9 @@ func newParser(r io.Reader) *parser {
 		'=': p.parseInfix,
 		'>': p.parseInfix,
 		'<': p.parseInfix,
+		'&': p.parseLogical,
+		'|': p.parseLogical,
+		'?': p.parseConditional,
 	}
 	return p
 }
@@ -129,6 +132,24 @@ func (p *parser) parseInfix(left ast) ast {
 	return infix{left: left, op: operator(op), right: p.parseExpression(prec)}
 }
 
+func (p *parser) parseLogical(left ast) ast {
+	op := p.peek()
+	// logical operators are written as && and ||
+	p.eat(op)
+	p.eat(op)
+	prec := precedence(op)
+	return logical{left: left, op: operator(op), right: p.parseExpression(prec)}
+}
+
+func (p *parser) parseConditional(left ast) ast {
+	p.eat('?')
+	then := p.parseExpression(lowest)
+	p.eat(':')
+	// parse the else branch with the lowest precedence
+	// to make the conditional operator right associative.
+	return conditional{cond: left, then: then, els: p.parseExpression(lowest)}
+}
+
 func (p *parser) parseStr() ast {
 	_, s := p.eat(scanner.String)
 	s, err := strconv.Unquote(s)
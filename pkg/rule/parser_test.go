@@ -51,6 +51,19 @@ func TestParser(t *testing.T) {
 		{"max(1,2,3", "", true},
 		{`max({"abc","def"},1/2-3)`, `max({"abc","def"},((1.00/2.00)-3.00))`, false},
 		{`min(len({"a","b"}),cs("foo"))`, `min(len({"a","b"}),cs("foo"))`, false},
+		{"true && false", "(true&&false)", false},
+		{"true || false", "(true||false)", false},
+		{"true || false && true", "(true||(false&&true))", false},
+		{"1 < 2 && 3 = 4", "((1.00<2.00)&&(3.00=4.00))", false},
+		{"true ? 1 : 2", "(true?1.00:2.00)", false},
+		{"1 < 2 ? 1 + 2 : 3 * 4", "((1.00<2.00)?(1.00+2.00):(3.00*4.00))", false},
+		{"a || b ? 1 : 2", "", true},
+		{"true ? false ? 1 : 2 : 3", "(true?(false?1.00:2.00):3.00)", false},
+		{"true ? 1 : false ? 2 : 3", "(true?1.00:(false?2.00:3.00))", false},
+		{"true && false || true", "((true&&false)||true)", false},
+		{"true ? 1", "", true},
+		{"true | false", "", true},
+		{"true & false", "", true},
 	}
 	for _, tc := range tests {
 		t.Run(tc.test, func(t *testing.T) {
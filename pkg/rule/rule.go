@@ -2,9 +2,12 @@ package rule
 
 import (
 	"errors"
+	"fmt"
+	"sort"
 	"strings"
 
 	"bitbucket.org/fflo/semix/pkg/memory"
+	"bitbucket.org/fflo/semix/pkg/semix"
 )
 
 // Map maps concept URLs to compiled rules.
@@ -23,22 +26,86 @@ func NewMap(rs map[string]string, lookup func(string) int) (Map, error) {
 	return m, nil
 }
 
+// Error represents an error that occurred while compiling
+// the rule of a concept.
+type Error struct {
+	URL, Expr string
+	Err       error
+}
+
+func (e Error) Error() string {
+	return fmt.Sprintf("invalid rule for %s: %q: %v", e.URL, e.Expr, e.Err)
+}
+
+// Update compiles a new Map from a map of rules.
+// If a rule cannot be compiled, the according rule of m is kept
+// (if it exists) and an Error is reported for the failed rule.
+// The map m is not changed.
+func (m Map) Update(rs map[string]string, lookup func(string) int) (Map, []Error) {
+	n := make(Map, len(rs))
+	var errs []Error
+	for url, str := range rs {
+		r, err := Compile(str, lookup)
+		if err != nil {
+			errs = append(errs, Error{URL: url, Expr: str, Err: err})
+			if old, ok := m[url]; ok {
+				n[url] = old
+			}
+			continue
+		}
+		n[url] = r
+	}
+	sort.Slice(errs, func(i, j int) bool {
+		return errs[i].URL < errs[j].URL
+	})
+	return n, errs
+}
+
 // Rule represents a compiled rule.
-type Rule []instruction
+// A rule holds its compiled instructions, which are used to
+// print and trace the rule, and an equivalent constant folded
+// closure, which is used to execute the rule.
+type Rule struct {
+	prog program
+	fn   numFunc
+}
 
 // Execute executes a rule and returns its result.
-func (r Rule) Execute(memory *memory.Memory) float64 {
-	stack := new(stack)
-	for _, o := range r {
-		o.call(memory, stack)
+// The graph is used to look up concepts for the graph functions.
+// It may be nil, if the rule does not use any graph functions.
+func (r Rule) Execute(memory *memory.Memory, g *semix.Graph) float64 {
+	if r.fn == nil {
+		return r.interpret(memory, g)
 	}
+	return r.fn(memory, g)
+}
+
+// interpret executes the rule's instructions.
+func (r Rule) interpret(memory *memory.Memory, g *semix.Graph) float64 {
+	stack := r.prog.run(memory, g)
 	return stack.pop1()
 }
 
 func (r Rule) String() string {
+	return r.prog.String()
+}
+
+// program is a list of instructions.
+type program []instruction
+
+// run executes the instructions and returns the resulting stack.
+func (p program) run(memory *memory.Memory, g *semix.Graph) *stack {
+	stack := new(stack)
+	for pc := 0; pc < len(p); {
+		pc += p[pc].call(memory, g, stack)
+	}
+	return stack
+}
+
+func (p program) String() string {
 	var strs []string
-	for _, rule := range r {
-		strs = append(strs, rule.String())
+	for _, instr := range p {
+		strs = append(strs, instr.String())
 	}
 	return strings.Join(strs, ";") + ";"
 }
@@ -62,9 +129,10 @@ func Compile(expr string, lookup func(string) int) (r Rule, err error) {
 	}()
 	ast, err := newParser(strings.NewReader(expr)).parse()
 	if err != nil {
-		return nil, err
+		return Rule{}, err
 	}
 	ast.check()
-	r = ast.compile(lookup)
+	r.prog = ast.compile(lookup)
+	r.fn = closureCompiler{lookup: lookup}.rule(ast)
 	return r, nil
 }
@@ -68,12 +68,24 @@ func TestSyntaxCheck(t *testing.T) {
 		{"max()", astNum, false},
 		{"min(1.0,false,false,true)", astNum, false},
 		{"min(true,false,1.0,true)", astNum, false},
+		{`w("a")`, astNum, false},
+		{`ws("a")+1`, astNum, false},
 		{`len(es()-{"topnode"})`, astNum, false},
 		{`len("abc")`, astNum, false},
 		{"min(true,false,false,true)", astNum, false},
 		{`cs({"a","b"})`, astSet, false},
 		{`e()+es()`, astSet, false},
+		{"true&&false", astBoolean, false},
+		{"true||1<2", astBoolean, false},
+		{"true?1:2", astNum, false},
+		{"1<2?true:false", astBoolean, false},
+		{`true?{"a"}:e()`, astSet, false},
 		// errors
+		{"1&&true", 0, true},
+		{"true||2", 0, true},
+		{"1?1:2", 0, true},
+		{"true?1:false", 0, true},
+		{`true?"a":"b"`, 0, true},
 		{"2-true", 0, true},
 		{"false+2", 0, true},
 		{"false/true", 0, true},
@@ -146,6 +158,12 @@ func TestCompileRule(t *testing.T) {
 		{`c("a")+cs("b")`, "PUSH 1;SC;PUSH 2;SCS;ADD;"},
 		{`c({"a","b"})`, "PUSH 1;PUSH 2;PUSH 2;C;"},
 		{`cs({"a","b"})`, "PUSH 1;PUSH 2;PUSH 2;CS;"},
+		{`w("a")>ws("b")`, "PUSH 1;W;PUSH 2;WS;GT;"},
+		{"true&&false", "PUSH true;JF 3;PUSH false;JMP 2;PUSH false;"},
+		{"true||false", "PUSH true;JF 3;PUSH true;JMP 2;PUSH false;"},
+		{"1<2&&n()>3", "PUSH 1.00;PUSH 2.00;LT;JF 5;MN;PUSH 3.00;GT;JMP 2;PUSH false;"},
+		{"true?1:2", "PUSH true;JF 3;PUSH 1.00;JMP 2;PUSH 2.00;"},
+		{`false?{"a"}:{}`, "PUSH false;JF 4;PUSH 1;PUSH 1;JMP 2;PUSH 0;"},
 	}
 	for _, tc := range tests {
 		t.Run(tc.test, func(t *testing.T) {
@@ -234,12 +252,36 @@ func TestExecuteRule(t *testing.T) {
 		{`cs("c")=0`, 1, false},
 		{`max(c({"a","b"}))=2`, 1, false},
 		{`min(cs({"b","c"}))=0`, 1, false},
+		{`w("a")`, 2, false},
+		{`ws("b")`, 1, false},
+		{`w("c")`, 0, false},
+		{"true&&true", 1, false},
+		{"true&&false", 0, false},
+		{"false&&true", 0, false},
+		{"false&&false", 0, false},
+		{"true||true", 1, false},
+		{"true||false", 1, false},
+		{"false||true", 1, false},
+		{"false||false", 0, false},
+		{"false||false||true", 1, false},
+		{"true&&true&&false", 0, false},
+		{`c("a")=2&&c("b")=1`, 1, false},
+		{`c("a")=1||c("b")=1`, 1, false},
+		{`c("a")>0?1:2`, 1, false},
+		{`c("c")>0?1:2`, 2, false},
+		{`(c("c")>0?1:2)+3`, 5, false},
+		{`c("c")>0?1:c("b")>0?2:3`, 2, false},
+		{`len(c("a")>0?{"a","b"}:{})=2`, 1, false},
+		{`len(false?{"a","b"}:{})=0`, 1, false},
+		{`(true?false:true)||(false?false:true)`, 1, false},
 		// errors
 		{"-{}", 0, true},
 		{"-es()", 0, true},
 		{`{"a","not","b"}`, 0, true},
 		{`cs("x")`, 0, true},
 		{`c({"x"})`, 0, true},
+		{`w({"a"})`, 0, true},
+		{`ws()`, 0, true},
 	}
 	for _, tc := range tests {
 		t.Run(tc.test, func(t *testing.T) {
@@ -253,9 +295,225 @@ func TestExecuteRule(t *testing.T) {
 			if err != nil {
 				t.Fatalf("got error: %s", err)
 			}
-			if got := rule.Execute(testMemory()); got != tc.want {
+			if got := rule.Execute(testMemory(), nil); got != tc.want {
 				t.Fatalf("expected %f; got %f", tc.want, got)
 			}
+			if got := rule.interpret(testMemory(), nil); got != tc.want {
+				t.Fatalf("expected %f; got %f (interpreted)", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestTraceRule(t *testing.T) {
+	tests := []struct {
+		test, want string
+		score      float64
+		mem        []string
+	}{
+		{"1+2", "PUSH 1.00;PUSH 2.00;ADD;", 3, nil},
+		{"false&&n()>3", "PUSH false;JF 5;PUSH false;", 0, nil},
+		{`c("b")=1`, "PUSH 2;SC;PUSH 1.00;EQ;", 1, []string{"a", "b", "a"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.test, func(t *testing.T) {
+			rule, err := Compile(tc.test, testLookupID)
+			if err != nil {
+				t.Fatalf("got error: %s", err)
+			}
+			score, steps := rule.Trace(testMemory(), nil)
+			if score != tc.score {
+				t.Fatalf("expected %f; got %f", tc.score, score)
+			}
+			if want := rule.Execute(testMemory(), nil); score != want {
+				t.Fatalf("expected %f; got %f", want, score)
+			}
+			var strs []string
+			var mem []string
+			for _, step := range steps {
+				strs = append(strs, step.Instruction)
+				mem = append(mem, step.Memory...)
+			}
+			if got := strings.Join(strs, ";") + ";"; got != tc.want {
+				t.Fatalf("expected %q; got %q", tc.want, got)
+			}
+			if got, want := strings.Join(mem, ","), strings.Join(tc.mem, ","); got != want {
+				t.Fatalf("expected %q; got %q", want, got)
+			}
+			if got := steps[len(steps)-1].Stack; len(got) != 1 || got[0] != score {
+				t.Fatalf("invalid stack: %v", got)
+			}
+		})
+	}
+}
+
+func TestGraphFunctions(t *testing.T) {
+	g := semix.NewGraph()
+	g.Add("dog", "isa", "mammal")
+	g.Add("cat", "isa", "mammal")
+	g.Add("mammal", "isa", "animal")
+	g.Add("dog", "likes", "bone")
+	g.Add("fish", "isa", "animal")
+	lookup := func(str string) int {
+		if c, ok := g.FindByURL(str); ok {
+			return int(c.ID())
+		}
+		return -1
+	}
+	mem := memory.New(5)
+	for _, url := range []string{"dog", "bone", "dog"} {
+		c, _ := g.FindByURL(url)
+		mem.Push(c)
+	}
+	tests := []struct {
+		test  string
+		want  float64
+		iserr bool
+	}{
+		{`isa("mammal")`, 1, false},
+		{`isa("animal","isa")`, 1, false},
+		{`isa("cat")`, 0, false},
+		{`isa("bone","isa")`, 1, false},
+		{`under("animal")`, 2, false},
+		{`under("bone")`, 3, false},
+		{`under("bone","isa")`, 1, false},
+		{`under("mammal","likes")`, 0, false},
+		{`dist("animal")`, 2, false},
+		{`dist("bone")`, 0, false},
+		{`dist("cat","animal")`, 2, false},
+		{`dist("animal","cat")`, 2, false},
+		{`dist("cat","dog")`, 2, false},
+		{`dist("bone","dog")`, 1, false},
+		{`dist("bone","cat")`, math.MaxFloat64, false},
+		{`dist("cat")`, 2, false},
+		{`dist("mammal")`, 1, false},
+		{`isa("mammal")&&dist("animal")<3`, 1, false},
+		{`dist("fish","cat")`, 3, false},
+		// errors
+		{`isa()`, 0, true},
+		{`isa(1)`, 0, true},
+		{`under("dog","isa","cat")`, 0, true},
+		{`dist({"dog"})`, 0, true},
+		{`dist("horse")`, 0, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.test, func(t *testing.T) {
+			rule, err := Compile(tc.test, lookup)
+			if tc.iserr {
+				if err == nil {
+					t.Fatalf("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("got error: %s", err)
+			}
+			if got := rule.Execute(mem, g); got != tc.want {
+				t.Fatalf("expected %f; got %f", tc.want, got)
+			}
+			if got := rule.interpret(mem, g); got != tc.want {
+				t.Fatalf("expected %f; got %f (interpreted)", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestUpdateMap(t *testing.T) {
+	m, err := NewMap(map[string]string{
+		"x": `c("a")>0`,
+		"y": `c("b")>1`,
+		"z": `cs("c")>0`,
+	}, testLookupID)
+	if err != nil {
+		t.Fatalf("got error: %s", err)
+	}
+	n, errs := m.Update(map[string]string{
+		"x": `c("c")>0`,
+		"y": `c("d")>1`,
+		"w": `c("a"`,
+	}, testLookupID)
+	if len(errs) != 2 || errs[0].URL != "w" || errs[1].URL != "y" {
+		t.Fatalf("invalid errors: %v", errs)
+	}
+	tests := []struct {
+		url, want string
+		ok        bool
+	}{
+		{"x", `PUSH 3;SC;PUSH 0.00;GT;`, true},
+		{"y", m["y"].String(), true},
+		{"z", "", false},
+		{"w", "", false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.url, func(t *testing.T) {
+			r, ok := n[tc.url]
+			if ok != tc.ok {
+				t.Fatalf("expected %t; got %t", tc.ok, ok)
+			}
+			if ok && r.String() != tc.want {
+				t.Fatalf("expected %s; got %s", tc.want, r)
+			}
+		})
+	}
+	if got := m["x"].String(); got != `PUSH 1;SC;PUSH 0.00;GT;` {
+		t.Fatalf("original map changed: %s", got)
+	}
+}
+
+func BenchmarkExecuteRule(b *testing.B) {
+	benchmarks := []string{
+		`c("a")>0`,
+		`c("a")>2*3-4&&cs("b")>0`,
+		`len(e()*{"a","b","c"})>1`,
+		`max(c({"a","b","c"}))>1||min(cs({"a","b"}))>0`,
+		`c("c")>0?n()-len():log(exp(2))`,
+	}
+	for _, bm := range benchmarks {
+		rule, err := Compile(bm, testLookupID)
+		if err != nil {
+			b.Fatalf("got error: %s", err)
+		}
+		mem := testMemory()
+		b.Run("closure:"+bm, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				rule.Execute(mem, nil)
+			}
+		})
+		b.Run("interpreter:"+bm, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				rule.interpret(mem, nil)
+			}
+		})
+	}
+}
+
+func TestWeightedRule(t *testing.T) {
+	mem := memory.NewWeighted(5, memory.Decay{Type: memory.LinearDecay, Rate: 0.25})
+	mem.Push(semix.NewConcept("a", semix.WithID(1)))
+	mem.Push(semix.NewConcept("b", semix.WithID(2)))
+	mem.Push(semix.NewConcept("a", semix.WithID(1)))
+	// distances: a=3, b=2, a=1
+	tests := []struct {
+		test string
+		want float64
+	}{
+		{`w("a")`, 1},
+		{`ws("b")`, 0.5},
+		{`c("a")`, 2},
+		{`w("a")>w("b")`, 1},
+	}
+	for _, tc := range tests {
+		t.Run(tc.test, func(t *testing.T) {
+			rule, err := Compile(tc.test, testLookupID)
+			if err != nil {
+				t.Fatalf("got error: %s", err)
+			}
+			if got := rule.Execute(mem, nil); got != tc.want {
+				t.Fatalf("expected %f; got %f", tc.want, got)
+			}
+			if got := rule.interpret(mem, nil); got != tc.want {
+				t.Fatalf("expected %f; got %f (interpreted)", tc.want, got)
+			}
 		})
 	}
 }
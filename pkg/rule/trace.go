@@ -0,0 +1,51 @@
+package rule
+
+import (
+	"bitbucket.org/fflo/semix/pkg/memory"
+	"bitbucket.org/fflo/semix/pkg/semix"
+)
+
+// Step represents one executed instruction of a traced rule.
+// Stack holds the state of the stack after the instruction was executed
+// and Memory holds the URLs of the memory's concepts that
+// were read by the instruction.
+type Step struct {
+	PC          int
+	Instruction string
+	Stack       []float64
+	Memory      []string
+}
+
+// Trace executes a rule like Execute and records
+// each executed instruction.
+func (r Rule) Trace(mem *memory.Memory, g *semix.Graph) (float64, []Step) {
+	stack := new(stack)
+	var steps []Step
+	for pc := 0; pc < len(r.prog); {
+		next := r.prog[pc].call(mem, g, stack)
+		steps = append(steps, Step{
+			PC:          pc,
+			Instruction: r.prog[pc].String(),
+			Stack:       append([]float64{}, (*stack)...),
+			Memory:      r.prog[pc].read(mem),
+		})
+		pc += next
+	}
+	return stack.pop1(), steps
+}
+
+// read returns the URLs of the concepts in the memory
+// that are read by the instruction.
+func (i instruction) read(mem *memory.Memory) []string {
+	var urls []string
+	f := func(c *semix.Concept) {
+		urls = append(urls, c.URL())
+	}
+	switch i.opcode {
+	case opSC, opC, opE, opISA, opUNDER, opMemDIST, opW:
+		mem.Each(f)
+	case opSCS, opCS, opES, opWS:
+		mem.EachS(f)
+	}
+	return urls
+}
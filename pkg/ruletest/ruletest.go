@@ -0,0 +1,280 @@
+// Package ruletest implements declarative test suites
+// for disambiguation rules.
+//
+// A test suite is a toml file that contains a list of test cases:
+//
+//	[[case]]
+//	name = "a name for the case"
+//	url = "http://example.org/concept" # or rule = "expression"
+//	size = 5 # size of the memory; defaults to the length of the context
+//	context = ["http://example.org/a", "http://example.org/b"]
+//	truth = true # or score = 1.5
+package ruletest
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"bitbucket.org/fflo/semix/pkg/rule"
+	"bitbucket.org/fflo/semix/pkg/searcher"
+	"bitbucket.org/fflo/semix/pkg/semix"
+	"github.com/BurntSushi/toml"
+)
+
+// Epsilon defines the tolerance for the comparison of expected scores.
+const Epsilon = 1e-9
+
+// Case represents a test case for a rule.
+// Either the rule's expression (Rule) or the URL of the concept
+// of the rule (URL) must be given. If both are given, the
+// expression is used. The context concepts are pushed into
+// a memory of the given size. Either the expected truth value
+// or the expected score must be set. As in the ruled resolver,
+// a rule is true if its score is >= 1.
+type Case struct {
+	Name, Rule, URL string
+	Size            int
+	Context         []string
+	Truth           *bool
+	Score           *float64
+}
+
+func (c Case) String() string {
+	if c.Name != "" {
+		return c.Name
+	}
+	if c.Rule != "" {
+		return c.Rule
+	}
+	return c.URL
+}
+
+// Suite represents a named list of test cases.
+type Suite struct {
+	Name  string
+	Cases []Case `toml:"case"`
+}
+
+// Read reads a test suite from a toml file.
+// The name of the suite is set to the path of the file.
+func Read(path string) (*Suite, error) {
+	var s Suite
+	if _, err := toml.DecodeFile(path, &s); err != nil {
+		return nil, err
+	}
+	s.Name = path
+	return &s, nil
+}
+
+// Runner runs test suites against a rule map.
+// The searcher is used to look up the concepts of the cases
+// and the graph is used by the graph functions of the rules.
+type Runner struct {
+	Rules    rule.Map
+	Graph    *semix.Graph
+	Searcher searcher.Searcher
+}
+
+// Run runs all cases of the given suite.
+func (r Runner) Run(s *Suite) Report {
+	report := Report{Name: s.Name, Results: make([]Result, len(s.Cases))}
+	start := time.Now()
+	for i, c := range s.Cases {
+		report.Results[i] = r.run(c)
+	}
+	report.Time = time.Since(start)
+	return report
+}
+
+func (r Runner) run(c Case) Result {
+	start := time.Now()
+	res := Result{Case: c}
+	res.Score, res.Err = r.execute(c)
+	res.Time = time.Since(start)
+	return res
+}
+
+func (r Runner) execute(c Case) (float64, error) {
+	if c.Truth == nil && c.Score == nil {
+		return 0, fmt.Errorf("missing expected truth value or score")
+	}
+	rl, ok := r.Rules[c.URL]
+	if c.Rule != "" {
+		var err error
+		if rl, err = rule.Compile(c.Rule, r.Searcher.LookupID); err != nil {
+			return 0, fmt.Errorf("invalid rule %q: %v", c.Rule, err)
+		}
+	} else if !ok {
+		return 0, fmt.Errorf("no rule for concept: %s", c.URL)
+	}
+	mem, err := r.Searcher.NewMemory(c.Size, c.Context)
+	if err != nil {
+		return 0, err
+	}
+	return rl.Execute(mem, r.Graph), nil
+}
+
+// Result represents the result of a test case.
+// Err is set if the case could not be executed.
+type Result struct {
+	Case  Case
+	Score float64
+	Err   error
+	Time  time.Duration
+}
+
+// Passed returns true if the case was executed and
+// the rule returned the expected result.
+func (r Result) Passed() bool {
+	if r.Err != nil {
+		return false
+	}
+	if r.Case.Truth != nil && *r.Case.Truth != (r.Score >= 1) {
+		return false
+	}
+	if r.Case.Score != nil && math.Abs(*r.Case.Score-r.Score) > Epsilon {
+		return false
+	}
+	return true
+}
+
+func (r Result) message() string {
+	if r.Err != nil {
+		return r.Err.Error()
+	}
+	if r.Case.Score != nil {
+		return fmt.Sprintf("expected score %g; got %g", *r.Case.Score, r.Score)
+	}
+	if r.Case.Truth != nil {
+		return fmt.Sprintf("expected %t; got %t (score %g)",
+			*r.Case.Truth, r.Score >= 1, r.Score)
+	}
+	return ""
+}
+
+// Report holds the results of a test suite.
+type Report struct {
+	Name    string
+	Results []Result
+	Time    time.Duration
+}
+
+// Failures returns the number of failed cases.
+func (r Report) Failures() int {
+	var n int
+	for _, res := range r.Results {
+		if !res.Passed() && res.Err == nil {
+			n++
+		}
+	}
+	return n
+}
+
+// Errors returns the number of cases that could not be executed.
+func (r Report) Errors() int {
+	var n int
+	for _, res := range r.Results {
+		if res.Err != nil {
+			n++
+		}
+	}
+	return n
+}
+
+// WriteText writes a human readable pass/fail report of the
+// given reports to w.
+func WriteText(w io.Writer, reports ...Report) error {
+	var total, failed int
+	for _, r := range reports {
+		for i, res := range r.Results {
+			status := "PASS"
+			if !res.Passed() {
+				status = "FAIL"
+				failed++
+			}
+			total++
+			if _, err := fmt.Fprintf(w, "%s %s:%d: %s\n",
+				status, r.Name, i+1, res.Case); err != nil {
+				return err
+			}
+			if !res.Passed() {
+				if _, err := fmt.Fprintf(w, "\t%s\n", res.message()); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	_, err := fmt.Fprintf(w, "%d/%d passed, %d failed\n", total-failed, total, failed)
+	return err
+}
+
+type junitSuites struct {
+	XMLName xml.Name     `xml:"testsuites"`
+	Suites  []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Errors   int         `xml:"errors,attr"`
+	Time     string      `xml:"time,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+}
+
+// WriteJUnit writes the given reports as JUnit XML to w.
+func WriteJUnit(w io.Writer, reports ...Report) error {
+	var suites junitSuites
+	for _, r := range reports {
+		suite := junitSuite{
+			Name:     r.Name,
+			Tests:    len(r.Results),
+			Failures: r.Failures(),
+			Errors:   r.Errors(),
+			Time:     seconds(r.Time),
+		}
+		for _, res := range r.Results {
+			c := junitCase{
+				Name:      res.Case.String(),
+				ClassName: r.Name,
+				Time:      seconds(res.Time),
+			}
+			if res.Err != nil {
+				c.Error = &junitMessage{Message: res.message()}
+			} else if !res.Passed() {
+				c.Failure = &junitMessage{Message: res.message()}
+			}
+			suite.Cases = append(suite.Cases, c)
+		}
+		suites.Suites = append(suites.Suites, suite)
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	e := xml.NewEncoder(w)
+	e.Indent("", "\t")
+	if err := e.Encode(suites); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
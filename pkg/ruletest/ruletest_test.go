@@ -0,0 +1,96 @@
+package ruletest
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"bitbucket.org/fflo/semix/pkg/rule"
+	"bitbucket.org/fflo/semix/pkg/searcher"
+	"bitbucket.org/fflo/semix/pkg/semix"
+)
+
+func testRunner(t *testing.T) Runner {
+	g := semix.NewGraph()
+	g.Add("http://example.org/dog", "http://example.org/likes", "http://example.org/bone")
+	g.Add("http://example.org/cat", "http://example.org/likes", "http://example.org/bone")
+	s := searcher.New(g, semix.Dictionary{})
+	rules, err := rule.NewMap(map[string]string{
+		"http://example.org/dog": `c("http://example.org/bone")>0`,
+	}, s.LookupID)
+	if err != nil {
+		t.Fatalf("got error: %s", err)
+	}
+	return Runner{Rules: rules, Graph: g, Searcher: s}
+}
+
+func TestRun(t *testing.T) {
+	s, err := Read("testdata/test.toml")
+	if err != nil {
+		t.Fatalf("got error: %s", err)
+	}
+	r := testRunner(t).Run(s)
+	tests := []struct {
+		passed, err bool
+	}{
+		{true, false},
+		{false, false},
+		{true, false},
+		{false, true},
+		{false, true},
+		{false, true},
+	}
+	if len(r.Results) != len(tests) {
+		t.Fatalf("expected %d results; got %d", len(tests), len(r.Results))
+	}
+	for i, tc := range tests {
+		t.Run(r.Results[i].Case.String(), func(t *testing.T) {
+			if got := r.Results[i].Passed(); got != tc.passed {
+				t.Fatalf("expected %t; got %t", tc.passed, got)
+			}
+			if got := r.Results[i].Err != nil; got != tc.err {
+				t.Fatalf("expected error %t; got %t", tc.err, got)
+			}
+		})
+	}
+	if got := r.Failures(); got != 1 {
+		t.Fatalf("expected 1 failure; got %d", got)
+	}
+	if got := r.Errors(); got != 3 {
+		t.Fatalf("expected 3 errors; got %d", got)
+	}
+}
+
+func TestWriteReports(t *testing.T) {
+	s, err := Read("testdata/test.toml")
+	if err != nil {
+		t.Fatalf("got error: %s", err)
+	}
+	r := testRunner(t).Run(s)
+	var text bytes.Buffer
+	if err := WriteText(&text, r); err != nil {
+		t.Fatalf("got error: %s", err)
+	}
+	if !strings.HasSuffix(text.String(), "2/6 passed, 4 failed\n") {
+		t.Fatalf("invalid text report: %s", text.String())
+	}
+	var junit bytes.Buffer
+	if err := WriteJUnit(&junit, r); err != nil {
+		t.Fatalf("got error: %s", err)
+	}
+	var suites junitSuites
+	if err := xml.Unmarshal(junit.Bytes(), &suites); err != nil {
+		t.Fatalf("got error: %s", err)
+	}
+	if len(suites.Suites) != 1 {
+		t.Fatalf("expected 1 test suite; got %d", len(suites.Suites))
+	}
+	suite := suites.Suites[0]
+	if suite.Tests != 6 || suite.Failures != 1 || suite.Errors != 3 {
+		t.Fatalf("invalid test suite: %+v", suite)
+	}
+	if suite.Cases[1].Failure == nil || suite.Cases[0].Failure != nil {
+		t.Fatalf("invalid test cases: %+v", suite.Cases)
+	}
+}
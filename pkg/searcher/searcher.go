@@ -1,21 +1,39 @@
 package searcher
 
 import (
+	"fmt"
 	"strings"
 
+	"bitbucket.org/fflo/semix/pkg/memory"
 	"bitbucket.org/fflo/semix/pkg/semix"
 )
 
+// Option defines an option for a Searcher.
+type Option func(*Searcher)
+
+// WithNormalizer sets the normalizer that was used
+// to build the dictionary (see semix.Resource).
+func WithNormalizer(n semix.Normalizer) Option {
+	return func(s *Searcher) {
+		s.normalizer = n
+	}
+}
+
 // New create a new Searcher instance.
-func New(g *semix.Graph, d semix.Dictionary) Searcher {
-	return Searcher{graph: g, dict: d}
+func New(g *semix.Graph, d semix.Dictionary, opts ...Option) Searcher {
+	s := Searcher{graph: g, dict: d}
+	for _, opt := range opts {
+		opt(&s)
+	}
+	return s
 }
 
 // Searcher holds a graph and a dictionary to search for concepts
 // by name and URL.
 type Searcher struct {
-	graph *semix.Graph
-	dict  semix.Dictionary
+	graph      *semix.Graph
+	dict       semix.Dictionary
+	normalizer semix.Normalizer
 }
 
 // FindByID mimics the graph searching interface.
@@ -56,7 +74,7 @@ func (s Searcher) SearchConcepts(q string, n int) []*semix.Concept {
 	if c, ok := s.graph.FindByURL(q); ok {
 		return []*semix.Concept{c}
 	}
-	if id, ok := s.dict[semix.NormalizeString(q, false)]; ok {
+	if id, ok := s.dict[s.normalizer.NormalizeString(q, false)]; ok {
 		if c, ok := s.graph.FindByID(id); ok {
 			return []*semix.Concept{c}
 		}
@@ -64,6 +82,54 @@ func (s Searcher) SearchConcepts(q string, n int) []*semix.Concept {
 	return s.searchMatchingConcepts(q, n)
 }
 
+// LookupID returns the ID of the one concept that matches the
+// given query string. If no or more than one concept
+// match the query string, -1 is returned.
+func (s Searcher) LookupID(q string) int {
+	cs := s.SearchConcepts(q, 2)
+	if len(cs) != 1 {
+		return -1
+	}
+	return int(cs[0].ID())
+}
+
+// LookupIDs returns the IDs of the concepts that match the given
+// query string. At most two IDs are returned, which is enough
+// to tell unresolved, unique and ambiguous queries apart.
+func (s Searcher) LookupIDs(q string) []int {
+	cs := s.SearchConcepts(q, 2)
+	ids := make([]int, len(cs))
+	for i, c := range cs {
+		ids[i] = int(c.ID())
+	}
+	return ids
+}
+
+// NewMemory returns a new memory of size n that contains the given
+// concepts. The concepts are given by their URLs or by queries that
+// match exactly one concept (see LookupID). If n <= 0, the size of
+// the memory is the number of the given concepts (at least 1).
+func (s Searcher) NewMemory(n int, strs []string) (*memory.Memory, error) {
+	if n <= 0 {
+		n = len(strs)
+	}
+	if n <= 0 {
+		n = 1
+	}
+	mem := memory.New(n)
+	for _, str := range strs {
+		c, ok := s.FindByURL(str)
+		if id := s.LookupID(str); !ok && id > 0 {
+			c, ok = s.FindByID(id)
+		}
+		if !ok {
+			return nil, fmt.Errorf("cannot find concept: %s", str)
+		}
+		mem.Push(c)
+	}
+	return mem, nil
+}
+
 // SearchParents searches maximal n parent concepts of a given URL.
 // If n < 0, all matching concepts are returned.
 func (s Searcher) SearchParents(c *semix.Concept, n int) []*semix.Concept {
@@ -109,7 +175,7 @@ func abs(id int32) int32 {
 }
 
 func (s Searcher) searchMatchingConcepts(q string, n int) []*semix.Concept {
-	normalized := semix.NormalizeString(q, false)
+	normalized := s.normalizer.NormalizeString(q, false)
 	set := make(map[string]bool)
 	var res []*semix.Concept
 	addToResults := func(c *semix.Concept) {
@@ -0,0 +1,293 @@
+package semix
+
+import (
+	"bytes"
+	"encoding/gob"
+	"sort"
+)
+
+// AhoCorasick is an Aho-Corasick automaton over the entries of a
+// dictionary. It maps the ids of the entries to Concepts. Like the
+// entries of a DFA, the entries are surrounded by one whitespace.
+type AhoCorasick struct {
+	ac    *ahoCorasick
+	graph *Graph
+}
+
+// maxDenseStates is the maximal number of states
+// with a dense transition table (1 MiB).
+const maxDenseStates = 1024
+
+// ahoCorasick stores the automaton in flat arrays. The states are
+// numbered in breadth first order. The transitions of state s are
+// the sorted bytes Chars[Edges[s]:Edges[s+1]] with their target
+// states Targets[Edges[s]:Edges[s+1]]. The first len(dense)/256
+// states additionally hold all their transitions (including the
+// ones that follow the failure links) in dense[s<<8|c].
+type ahoCorasick struct {
+	Edges   []int32
+	Chars   []byte
+	Targets []int32
+	dense   []int32
+	// Fail holds the failure links and Out the links to the
+	// next state with an output along the failure links.
+	Fail, Out []int32
+	// Depth holds the length of the states and IDs the encoded
+	// IDs of the entries (0 if the state is not final).
+	Depth, IDs []int32
+}
+
+// NewAhoCorasick constructs a new Aho-Corasick automaton.
+func NewAhoCorasick(d Dictionary, graph *Graph) AhoCorasick {
+	return newAhoCorasick(d, nil, graph)
+}
+
+// newAhoCorasick constructs a new Aho-Corasick automaton
+// for the dictionary and its marked variants (see Resource).
+func newAhoCorasick(d, v Dictionary, graph *Graph) AhoCorasick {
+	return newAhoCorasickWithDense(d, v, graph, maxDenseStates)
+}
+
+// newAhoCorasickWithDense constructs a new Aho-Corasick automaton
+// with at most n states with a dense transition table. The root
+// state always has a dense transition table.
+func newAhoCorasickWithDense(d, v Dictionary, graph *Graph, n int) AhoCorasick {
+	type pair struct {
+		id  int32
+		str string
+	}
+	var pairs []pair
+	for str, id := range d {
+		if id != 0 {
+			pairs = append(pairs, pair{id: id, str: " " + str + " "})
+		}
+	}
+	for str, id := range v {
+		if _, ok := d[str]; ok || id == 0 {
+			continue
+		}
+		pairs = append(pairs, pair{id: encodeVariantID(id), str: " " + str + " "})
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		return pairs[i].str < pairs[j].str
+	})
+	// build the trie
+	trie := []map[byte]int32{make(map[byte]int32)}
+	ids := []int32{0}
+	for _, p := range pairs {
+		var s int32
+		for i := 0; i < len(p.str); i++ {
+			t, ok := trie[s][p.str[i]]
+			if !ok {
+				t = int32(len(trie))
+				trie = append(trie, make(map[byte]int32))
+				ids = append(ids, 0)
+				trie[s][p.str[i]] = t
+			}
+			s = t
+		}
+		ids[s] = p.id
+	}
+	// renumber the states in breadth first order
+	if n > len(trie) {
+		n = len(trie)
+	}
+	if n < 1 {
+		n = 1
+	}
+	ac := &ahoCorasick{
+		Edges: make([]int32, 1, len(trie)+1),
+		dense: make([]int32, n<<8),
+		Fail:  make([]int32, len(trie)),
+		Out:   make([]int32, len(trie)),
+		Depth: make([]int32, len(trie)),
+		IDs:   make([]int32, len(trie)),
+	}
+	order := []int32{0}
+	for s := 0; s < len(order); s++ {
+		ac.IDs[s] = ids[order[s]]
+		cs := make([]byte, 0, len(trie[order[s]]))
+		for c := range trie[order[s]] {
+			cs = append(cs, c)
+		}
+		sort.Slice(cs, func(i, j int) bool { return cs[i] < cs[j] })
+		for _, c := range cs {
+			t := int32(len(order))
+			order = append(order, trie[order[s]][c])
+			ac.Depth[t] = ac.Depth[s] + 1
+			ac.Chars = append(ac.Chars, c)
+			ac.Targets = append(ac.Targets, t)
+		}
+		ac.Edges = append(ac.Edges, int32(len(ac.Chars)))
+	}
+	// calculate the failure links, the output links and the dense
+	// transitions in breadth first order: the failure link of a
+	// state always points to a state with a smaller number.
+	for s := int32(0); s < int32(len(order)); s++ {
+		for i := ac.Edges[s]; i < ac.Edges[s+1]; i++ {
+			t := ac.Targets[i]
+			if s != 0 {
+				ac.Fail[t] = ac.delta(ac.Fail[s], ac.Chars[i])
+			}
+			if f := ac.Fail[t]; ac.IDs[f] != 0 {
+				ac.Out[t] = f
+			} else {
+				ac.Out[t] = ac.Out[f]
+			}
+		}
+		if int(s) < n {
+			ac.denseRow(s)
+		}
+	}
+	return AhoCorasick{ac: ac, graph: graph}
+}
+
+// denseRow fills the dense transitions of state s. The dense
+// transitions of the failure link of s must be filled already.
+func (ac *ahoCorasick) denseRow(s int32) {
+	row := ac.dense[s<<8 : (s+1)<<8]
+	if s != 0 {
+		copy(row, ac.dense[ac.Fail[s]<<8:(ac.Fail[s]+1)<<8])
+	}
+	for i := ac.Edges[s]; i < ac.Edges[s+1]; i++ {
+		row[ac.Chars[i]] = ac.Targets[i]
+	}
+}
+
+// next returns the transition of s for c or -1.
+func (ac *ahoCorasick) next(s int32, c byte) int32 {
+	for i := ac.Edges[s]; i < ac.Edges[s+1] && ac.Chars[i] <= c; i++ {
+		if ac.Chars[i] == c {
+			return ac.Targets[i]
+		}
+	}
+	return -1
+}
+
+// delta executes one transition following the failure links.
+func (ac *ahoCorasick) delta(s int32, c byte) int32 {
+	for int(s) >= len(ac.dense)>>8 {
+		if t := ac.next(s, c); t >= 0 {
+			return t
+		}
+		s = ac.Fail[s]
+	}
+	return ac.dense[s<<8|int32(c)]
+}
+
+// output returns the first state with an output
+// that ends in s or 0 if no entry ends in s.
+func (ac *ahoCorasick) output(s int32) int32 {
+	if ac.IDs[s] != 0 {
+		return s
+	}
+	return ac.Out[s]
+}
+
+// concept returns the concept of the given encoded ID and
+// true iff the entry is a variant. It returns nil if the
+// concept cannot be found.
+func (a AhoCorasick) concept(id int32) (*Concept, bool) {
+	id, variant := decodeID(id)
+	c, ok := a.graph.FindByID(id)
+	if !ok {
+		return nil, false
+	}
+	return c, variant
+}
+
+// GobDecode decodes the automaton of an AhoCorasick.
+// It does not decode the graph.
+func (a *AhoCorasick) GobDecode(bs []byte) error {
+	decoder := gob.NewDecoder(bytes.NewBuffer(bs))
+	if err := decoder.Decode(&a.ac); err != nil {
+		return err
+	}
+	// the dense transitions are not encoded but rebuilt
+	n := maxDenseStates
+	if err := decoder.Decode(&n); eof(err) != nil {
+		return err
+	}
+	if n > len(a.ac.IDs) {
+		n = len(a.ac.IDs)
+	}
+	if n < 1 {
+		n = 1
+	}
+	a.ac.dense = make([]int32, n<<8)
+	for s := int32(0); s < int32(n); s++ {
+		a.ac.denseRow(s)
+	}
+	return nil
+}
+
+// GobEncode encodes the automaton of an AhoCorasick.
+// It does not encode the graph.
+func (a AhoCorasick) GobEncode() ([]byte, error) {
+	buffer := new(bytes.Buffer)
+	encoder := gob.NewEncoder(buffer)
+	if err := encoder.Encode(a.ac); err != nil {
+		return nil, err
+	}
+	if err := encoder.Encode(len(a.ac.dense) >> 8); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// AhoCorasickMatcher uses an Aho-Corasick automaton to search for
+// matches in a string. It finds the same matches as a DFAMatcher
+// for the same dictionary, but reads the string only once.
+type AhoCorasickMatcher struct {
+	AC AhoCorasick
+}
+
+// Match returns the MatchPos of the left most longest entry in the
+// string. The MatchPos denotes the first encountered concept in the
+// string or nil nothing could be matched.
+func (m AhoCorasickMatcher) Match(str string) MatchPos {
+	ac := m.AC.ac
+	var match MatchPos
+	var s int32
+	for i := 0; i < len(str); i++ {
+		s = ac.delta(s, str[i])
+		for o := ac.output(s); o != 0; o = ac.Out[o] {
+			b := i - int(ac.Depth[o]) + 2
+			if match.Concept != nil && (b > match.Begin || (b == match.Begin && i < match.End)) {
+				continue
+			}
+			if c, v := m.AC.concept(ac.IDs[o]); c != nil {
+				match = MatchPos{Concept: c, Begin: b, End: i, Variant: v}
+			}
+		}
+		// no remaining entry can begin before the found match
+		if match.Concept != nil && i-int(ac.Depth[s])+2 > match.Begin {
+			break
+		}
+	}
+	return match
+}
+
+// MatchAll returns the MatchPos of all entries in the string,
+// including nested and overlapping entries. The positions
+// are ordered by their begin and end positions.
+func (m AhoCorasickMatcher) MatchAll(str string) []MatchPos {
+	ac := m.AC.ac
+	var ms []MatchPos
+	var s int32
+	for i := 0; i < len(str); i++ {
+		s = ac.delta(s, str[i])
+		for o := ac.output(s); o != 0; o = ac.Out[o] {
+			if c, v := m.AC.concept(ac.IDs[o]); c != nil {
+				ms = append(ms, MatchPos{Concept: c, Begin: i - int(ac.Depth[o]) + 2, End: i, Variant: v})
+			}
+		}
+	}
+	sort.Slice(ms, func(i, j int) bool {
+		if ms[i].Begin == ms[j].Begin {
+			return ms[i].End < ms[j].End
+		}
+		return ms[i].Begin < ms[j].Begin
+	})
+	return ms
+}
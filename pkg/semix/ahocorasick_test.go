@@ -0,0 +1,132 @@
+package semix
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAhoCorasickMatcher(t *testing.T) {
+	dfa := makeDFA(t)
+	c1, _ := dfa.graph.FindByURL("match")
+	c2, _ := dfa.graph.FindByURL("match two")
+	m := AhoCorasickMatcher{AC: NewAhoCorasick(map[string]int32{
+		"match":       c1.ID(),
+		"mitch match": c2.ID(),
+	}, dfa.graph)}
+	tests := []struct {
+		test string
+		want MatchPos
+	}{
+		{"", MatchPos{}},
+		{" nothing to find", MatchPos{}},
+		{" here is the match ", MatchPos{Begin: 13, End: 18, Concept: c1}},
+		{" another match is here ", MatchPos{Begin: 9, End: 14, Concept: c1}},
+		{" here is mitch match ", MatchPos{Begin: 9, End: 20, Concept: c2}},
+		{" mitch match ", MatchPos{Begin: 1, End: 12, Concept: c2}},
+		{" mitch mitch match ", MatchPos{Begin: 7, End: 18, Concept: c2}},
+		{" matches match ", MatchPos{Begin: 9, End: 14, Concept: c1}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.test, func(t *testing.T) {
+			if pos := m.Match(tc.test); pos != tc.want {
+				t.Errorf("expeceted pos = %v; got %v", tc.want, pos)
+			}
+		})
+	}
+}
+
+func TestAhoCorasickEqualsDFA(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	words := []string{"a", "b", "ab", "ba", "aab"}
+	g, d := makeRandomDictionary(r, words, 200, 4)
+	v := Dictionary{"a ab": d["a"], "b b": -d["b"]}
+	dm := DFAMatcher{DFA: newDFA(d, v, g)}
+	// with only the root, some and all states with dense transitions
+	for _, n := range []int{1, 8, maxDenseStates} {
+		am := AhoCorasickMatcher{AC: newAhoCorasickWithDense(d, v, g, n)}
+		for i := 0; i < 200; i++ {
+			str := " " + makeRandomText(r, words, 1+r.Intn(20)) + " "
+			if want, got := dm.Match(str), am.Match(str); want != got {
+				t.Fatalf("%d %q: expected %v; got %v", n, str, want, got)
+			}
+			if want, got := dm.MatchAll(str), am.MatchAll(str); !reflect.DeepEqual(want, got) {
+				t.Fatalf("%d %q: expected %v; got %v", n, str, want, got)
+			}
+		}
+	}
+}
+
+func makeRandomDictionary(r *rand.Rand, words []string, n, k int) (*Graph, Dictionary) {
+	g := NewGraph()
+	d := make(Dictionary)
+	for _, word := range words {
+		c, _, _ := g.Add(word, "p", "o")
+		d[word] = c.ID()
+	}
+	for i := 0; i < n; i++ {
+		entry := makeRandomText(r, words, 1+r.Intn(k))
+		c, _, _ := g.Add(fmt.Sprintf("c%d", i), "p", "o")
+		d[entry] = c.ID()
+	}
+	return g, d
+}
+
+func makeRandomText(r *rand.Rand, words []string, n int) string {
+	strs := make([]string, n)
+	for i := range strs {
+		strs[i] = words[r.Intn(len(words))]
+	}
+	return strings.Join(strs, " ")
+}
+
+func BenchmarkMatcher(b *testing.B) {
+	r := rand.New(rand.NewSource(42))
+	words := make([]string, 2000)
+	for i := range words {
+		words[i] = fmt.Sprintf("w%d", i)
+	}
+	g, d := makeRandomDictionary(r, words, 100000, 4)
+	// entries with many common prefixes: x, x x, x x x, ...
+	prefixes := make(Dictionary)
+	for i := 1; i <= 50; i++ {
+		prefixes[strings.Repeat("x ", i)+"y"] = d[words[i]]
+	}
+	benchmarks := []struct {
+		name string
+		d    Dictionary
+		text string
+	}{
+		{"large", d, " " + makeRandomText(r, words, 10000) + " "},
+		{"prefixes", prefixes, " " + strings.Repeat("x ", 10000)},
+	}
+	for _, bm := range benchmarks {
+		matchers := []struct {
+			name string
+			m    AllMatcher
+		}{
+			{"dfa", DFAMatcher{DFA: NewDFA(bm.d, g)}},
+			{"ahocorasick", AhoCorasickMatcher{AC: NewAhoCorasick(bm.d, g)}},
+		}
+		for _, m := range matchers {
+			b.Run(bm.name+":"+m.name+":match", func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					for str := bm.text; ; {
+						pos := m.m.Match(str)
+						if pos.Concept == nil {
+							break
+						}
+						str = str[pos.End:]
+					}
+				}
+			})
+			b.Run(bm.name+":"+m.name+":all", func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					m.m.MatchAll(bm.text)
+				}
+			})
+		}
+	}
+}
@@ -16,7 +16,13 @@ type DFA struct {
 
 // NewDFA constructs a new DFA.
 func NewDFA(d Dictionary, graph *Graph) DFA {
-	return DFA{graph: graph, dfa: newSparseTableDFA(d)}
+	return newDFA(d, nil, graph)
+}
+
+// newDFA constructs a new DFA for the dictionary
+// and its marked variants (see Resource).
+func newDFA(d, v Dictionary, graph *Graph) DFA {
+	return DFA{graph: graph, dfa: newSparseTableDFA(d, v)}
 }
 
 // Initial returns the initial state of the DFA.
@@ -32,17 +38,45 @@ func (d DFA) Delta(s sparsetable.State, c byte) sparsetable.State {
 // Final return the found Concept and true iff s denotes a final state.
 // Otherwise it returns nil and false.
 func (d DFA) Final(s sparsetable.State) (*Concept, bool) {
+	c, _, ok := d.final(s)
+	return c, ok
+}
+
+// final returns the found Concept, true iff the Concept was
+// found using a variant and true iff s denotes a final state.
+func (d DFA) final(s sparsetable.State) (*Concept, bool, bool) {
 	data, final := d.dfa.Final(s)
 	if !final {
-		return nil, false
+		return nil, false, false
 	}
-	if c, ok := d.graph.FindByID(data); ok {
-		return c, true
+	id, variant := decodeID(data)
+	if c, ok := d.graph.FindByID(id); ok {
+		return c, variant, true
 	}
-	return nil, false
+	return nil, false, false
 }
 
-func newSparseTableDFA(d Dictionary) *sparsetable.DFA {
+// variantBit marks the IDs of variants in the DFA.
+const variantBit int32 = 1 << 30
+
+func encodeVariantID(id int32) int32 {
+	if id < 0 {
+		return -(-id | variantBit)
+	}
+	return id | variantBit
+}
+
+// decodeID returns the ID of the concept and true
+// iff the ID denotes a variant.
+func decodeID(id int32) (int32, bool) {
+	if id < 0 {
+		id, variant := decodeID(-id)
+		return -id, variant
+	}
+	return id &^ variantBit, id&variantBit != 0
+}
+
+func newSparseTableDFA(d, v Dictionary) *sparsetable.DFA {
 	type pair struct {
 		id  int32
 		str string
@@ -55,6 +89,12 @@ func newSparseTableDFA(d Dictionary) *sparsetable.DFA {
 		}
 		pairs = append(pairs, pair{id: id, str: " " + str + " "})
 	}
+	for str, id := range v {
+		if _, ok := d[str]; ok || id == 0 {
+			continue
+		}
+		pairs = append(pairs, pair{id: encodeVariantID(id), str: " " + str + " "})
+	}
 	sort.Slice(pairs, func(i, j int) bool {
 		return pairs[i].str < pairs[j].str
 	})
@@ -10,16 +10,39 @@ type DFAMatcher struct {
 // nothing could be matched.
 func (m DFAMatcher) Match(str string) MatchPos {
 	for i := 0; i < len(str); {
-		pos, c, _ := m.matchFromHere(str[i:])
+		pos, c, v := m.matchFromHere(str[i:])
 		// say.Info("match from here %q:{%d %s}", str[i:], pos, c)
 		if c != nil {
-			return MatchPos{Concept: c, Begin: i + 1, End: i + pos}
+			return MatchPos{Concept: c, Begin: i + 1, End: i + pos, Variant: v}
 		}
 		i = next(i, pos, str)
 	}
 	return MatchPos{}
 }
 
+// MatchAll returns the MatchPos of all entries in the DFA that
+// are found in the string, including nested and overlapping entries.
+// The positions are ordered by their begin and end positions.
+func (m DFAMatcher) MatchAll(str string) []MatchPos {
+	var ms []MatchPos
+	for i := 0; i < len(str); i++ {
+		if str[i] != ' ' {
+			continue
+		}
+		s := m.DFA.Initial()
+		for j := i; j < len(str); j++ {
+			s = m.DFA.Delta(s, str[j])
+			if !s.Valid() {
+				break
+			}
+			if c, v, f := m.DFA.final(s); f {
+				ms = append(ms, MatchPos{Concept: c, Begin: i + 1, End: j, Variant: v})
+			}
+		}
+	}
+	return ms
+}
+
 func next(i, pos int, str string) int {
 	if pos > 0 {
 		return i + pos
@@ -36,18 +59,20 @@ func (m DFAMatcher) matchFromHere(str string) (int, *Concept, bool) {
 	s := m.DFA.Initial()
 	var concept *Concept
 	var pos int
+	var variant bool
 	for i := 0; i < len(str); i++ {
 		s = m.DFA.Delta(s, str[i])
 		if !s.Valid() {
 			break
 		}
-		if c, f := m.DFA.Final(s); f {
+		if c, v, f := m.DFA.final(s); f {
 			concept = c
 			pos = i
+			variant = v
 		}
 		if pos == 0 && str[i] == ' ' {
 			pos = i
 		}
 	}
-	return pos, concept, false
+	return pos, concept, variant
 }
@@ -0,0 +1,128 @@
+package semix
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// EditCosts defines the costs of the edit operations of a FuzzyDFA.
+// Insert is the cost of an additional character in the text, Delete
+// the cost of a character of a label that is missing in the text and
+// Substitute the cost of replacing a character of a label in the text.
+// Confusions define additional (multi-character) substitutions with
+// their own costs. The error limit of a FuzzyDFA is the maximal sum
+// of the costs of all edit operations of a match.
+type EditCosts struct {
+	Insert, Delete, Substitute int
+	Confusions                 []Confusion
+}
+
+// Confusion defines the substitution of the string Label
+// in a label with the string Text in the text, e.g. the
+// OCR confusion of m in a label with rn in the text.
+type Confusion struct {
+	Label, Text string
+	Cost        int
+}
+
+// DefaultEditCosts returns the default edit costs.
+// All edit operations cost 1 and there are no confusions.
+func DefaultEditCosts() EditCosts {
+	return EditCosts{Insert: 1, Delete: 1, Substitute: 1}
+}
+
+// Check returns an error if the edit costs are invalid.
+// All costs must be positive and the strings of the
+// confusions must neither be empty nor contain whitespace.
+func (e EditCosts) Check() error {
+	if e.Insert <= 0 || e.Delete <= 0 || e.Substitute <= 0 {
+		return fmt.Errorf("invalid edit costs: costs must be positive")
+	}
+	for _, c := range e.Confusions {
+		if c.Label == "" || c.Text == "" || c.Label == c.Text ||
+			strings.ContainsRune(c.Label, ' ') || strings.ContainsRune(c.Text, ' ') {
+			return fmt.Errorf("invalid confusion %s/%s", c.Label, c.Text)
+		}
+		if c.Cost <= 0 {
+			return fmt.Errorf("invalid confusion %s/%s: cost must be positive",
+				c.Label, c.Text)
+		}
+	}
+	return nil
+}
+
+// Normalize returns the edit costs with the strings of the confusions
+// normalized by the given normalizer, since the confusions are applied
+// to normalized texts. Confusions that become identical are removed.
+func (e EditCosts) Normalize(n Normalizer) EditCosts {
+	cs := make([]Confusion, 0, len(e.Confusions))
+	for _, c := range e.Confusions {
+		c.Label = strings.TrimSpace(n.NormalizeString(c.Label, false))
+		c.Text = strings.TrimSpace(n.NormalizeString(c.Text, false))
+		if c.Label != c.Text {
+			cs = append(cs, c)
+		}
+	}
+	e.Confusions = cs
+	return e
+}
+
+// unit returns the minimal cost of a single character edit operation.
+func (e EditCosts) unit() int {
+	unit := e.Insert
+	if e.Delete < unit {
+		unit = e.Delete
+	}
+	if e.Substitute < unit {
+		unit = e.Substitute
+	}
+	return unit
+}
+
+var errInvalidLine = errors.New("invalid line")
+
+// ReadEditCosts reads a confusion table. Empty lines and lines
+// starting with # are ignored. The lines :insert <cost>, :delete
+// <cost> and :substitute <cost> set the costs of the single character
+// edit operations (default 1). All other lines define a confusion
+// <label> <text> <cost>, e.g. m rn 1. Since the confusions are applied
+// to the normalized text, they must not contain any whitespace.
+func ReadEditCosts(r io.Reader) (EditCosts, error) {
+	e := DefaultEditCosts()
+	s := bufio.NewScanner(r)
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		err := errInvalidLine
+		switch {
+		case len(fields) == 2 && fields[0] == ":insert":
+			e.Insert, err = strconv.Atoi(fields[1])
+		case len(fields) == 2 && fields[0] == ":delete":
+			e.Delete, err = strconv.Atoi(fields[1])
+		case len(fields) == 2 && fields[0] == ":substitute":
+			e.Substitute, err = strconv.Atoi(fields[1])
+		case len(fields) == 3 && !strings.HasPrefix(fields[0], ":"):
+			c := Confusion{Label: fields[0], Text: fields[1]}
+			c.Cost, err = strconv.Atoi(fields[2])
+			e.Confusions = append(e.Confusions, c)
+		}
+		// do not report the contents of the table
+		if err != nil {
+			return EditCosts{}, fmt.Errorf("invalid confusion table: line %d", n)
+		}
+	}
+	if err := s.Err(); err != nil {
+		return EditCosts{}, err
+	}
+	if err := e.Check(); err != nil {
+		return EditCosts{}, err
+	}
+	return e, nil
+}
@@ -0,0 +1,117 @@
+package semix
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadEditCosts(t *testing.T) {
+	tests := []struct {
+		test  string
+		want  EditCosts
+		iserr bool
+	}{
+		{"", DefaultEditCosts(), false},
+		{"# comment\n\n:insert 2\n:delete 3\n:substitute 4\n", EditCosts{Insert: 2, Delete: 3, Substitute: 4}, false},
+		{"m rn 1\nſ f 1", EditCosts{Insert: 1, Delete: 1, Substitute: 1, Confusions: []Confusion{
+			{Label: "m", Text: "rn", Cost: 1},
+			{Label: "ſ", Text: "f", Cost: 1},
+		}}, false},
+		{":insert 0", EditCosts{}, true},
+		{":insert x", EditCosts{}, true},
+		{":unknown 1", EditCosts{}, true},
+		{"m rn", EditCosts{}, true},
+		{"m m 1", EditCosts{}, true},
+		{"m rn 0", EditCosts{}, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.test, func(t *testing.T) {
+			got, err := ReadEditCosts(strings.NewReader(tc.test))
+			if tc.iserr {
+				if err == nil {
+					t.Fatalf("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("got error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("expected %v; got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestReadEditCostsError(t *testing.T) {
+	_, err := ReadEditCosts(strings.NewReader("m rn 1\nsecret line\n"))
+	if err == nil {
+		t.Fatalf("expected error")
+	}
+	if got, want := err.Error(), "invalid confusion table: line 2"; got != want {
+		t.Fatalf("expected %q; got %q", want, got)
+	}
+}
+
+func TestEditCostsNormalize(t *testing.T) {
+	n := Normalizer{Mappings: map[string]string{"ſ": "s"}, Fold: true}
+	costs := EditCosts{Insert: 1, Delete: 1, Substitute: 1, Confusions: []Confusion{
+		{Label: "M", Text: "RN", Cost: 1},
+		{Label: "ſ", Text: "s", Cost: 1},
+		{Label: "a.", Text: "a", Cost: 1},
+	}}
+	want := EditCosts{Insert: 1, Delete: 1, Substitute: 1, Confusions: []Confusion{
+		{Label: "m", Text: "rn", Cost: 1},
+	}}
+	if got := costs.Normalize(n); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v; got %v", want, got)
+	}
+	costs.Confusions = []Confusion{{Label: "a.b", Text: "ab", Cost: 1}}
+	if err := costs.Normalize(n).Check(); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+func TestWeightedFuzzyDFAMatcher(t *testing.T) {
+	graph := NewGraph()
+	c, _, _ := graph.Add("modern", "x", "y")
+	dfa := NewDFA(Dictionary{"modern": c.ID(), "holiday": c.ID()}, graph)
+	costs, err := ReadEditCosts(strings.NewReader(
+		":insert 2\n:delete 2\n:substitute 2\nm rn 1\nh li 1\n"))
+	if err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	tests := []struct {
+		test  string
+		k, l  int
+		match bool
+	}{
+		{" modern ", 1, 0, true},
+		{" rnodern ", 1, 1, true},
+		{" rnodern ", 0, 0, false},
+		{" xodern ", 1, 0, false},
+		{" xodern ", 2, 2, true},
+		{" rnodcrn ", 3, 3, true},
+		{" lioliday ", 1, 1, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.test, func(t *testing.T) {
+			m := FuzzyDFAMatcher{DFA: NewFuzzyDFA(tc.k, dfa, WithEditCosts(costs))}
+			pos := m.Match(tc.test)
+			if match := pos.Concept != nil; match != tc.match {
+				t.Fatalf("expected match=%t; got %t", tc.match, match)
+			}
+			if pos.Concept == nil {
+				return
+			}
+			var l int
+			if pos.Concept.Ambig() {
+				l = pos.Concept.EdgeAt(0).L
+			}
+			if l != tc.l {
+				t.Fatalf("expected cost %d; got %d", tc.l, l)
+			}
+		})
+	}
+}
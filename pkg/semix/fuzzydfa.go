@@ -2,42 +2,154 @@ package semix
 
 import (
 	"fmt"
+	"strings"
+	"unicode/utf8"
 
 	"bitbucket.org/fflo/sparsetable"
 )
 
-// FuzzyDFA is a simple wrapper around a sparsetable.FuzzyDFA.
+// FuzzyDFA is an approximate search over the entries of a DFA.
 // It maps the ids of the underlying sparsetable.DFA to the according Concepts.
+// The costs of the edit operations are defined by its EditCosts.
+// The edit operations work on unicode characters: k edits of
+// single characters cost k times the cost of the operation,
+// regardless of the length of their UTF-8 encoding.
 type FuzzyDFA struct {
-	dfa   *sparsetable.FuzzyDFA
+	dfa   *sparsetable.DFA
 	graph *Graph
+	k     int
+	costs EditCosts
 }
 
+// FuzzyDFAOption defines an option for a FuzzyDFA.
+type FuzzyDFAOption func(*FuzzyDFA)
+
+// WithEditCosts sets the edit costs of a FuzzyDFA. The edit costs
+// must be valid (see EditCosts.Check).
+func WithEditCosts(costs EditCosts) FuzzyDFAOption {
+	return func(d *FuzzyDFA) {
+		d.costs = costs
+	}
+}
+
+// MaxErrorLimit is the maximal error bound of a FuzzyDFA. The index
+// stores the errors of the matches with 6 bits.
+const MaxErrorLimit = 63
+
 // NewFuzzyDFA constructs a new FuzzyDFA with the given maximum error bound k.
-func NewFuzzyDFA(k int, dfa DFA) FuzzyDFA {
-	return FuzzyDFA{
-		dfa:   sparsetable.NewFuzzyDFA(k, dfa.dfa),
+// By default all edit operations cost 1 (see DefaultEditCosts).
+// Error bounds larger than MaxErrorLimit are set to MaxErrorLimit.
+func NewFuzzyDFA(k int, dfa DFA, opts ...FuzzyDFAOption) FuzzyDFA {
+	d := FuzzyDFA{
+		dfa:   dfa.dfa,
 		graph: dfa.graph,
+		k:     k,
+		costs: DefaultEditCosts(),
+	}
+	if d.k > MaxErrorLimit {
+		d.k = MaxErrorLimit
+	}
+	for _, opt := range opts {
+		opt(&d)
 	}
+	return d
 }
 
 // MaxError returns the maximum allowed error for the this FuzzyDFA.
 func (d FuzzyDFA) MaxError() int {
-	return d.dfa.MaxError()
+	return d.k
+}
+
+// EditCosts returns the edit costs of this FuzzyDFA.
+func (d FuzzyDFA) EditCosts() EditCosts {
+	return d.costs
 }
 
 // Initial returns the initial state of this FuzzyDFA.
-func (d FuzzyDFA) Initial(str string) *sparsetable.FuzzyStack {
-	return d.dfa.Initial(str)
+func (d FuzzyDFA) Initial(str string) *FuzzyStack {
+	s := &FuzzyStack{d: d, str: str}
+	s.push(fuzzyState{state: d.dfa.Initial()})
+	return s
 }
 
-// Delta executes one fuzzy transition in this FuzzyDFA.
-func (d FuzzyDFA) Delta(s *sparsetable.FuzzyStack, f func(int, int, *Concept)) bool {
-	return d.dfa.Delta(s, func(k, pos int, id int32) {
+// Delta executes one fuzzy transition in this FuzzyDFA. If a final state
+// is encountered, the callback function is called with the active error,
+// the next position in the string and the concept of the final state.
+// Delta returns false if no more transitions can be done.
+func (d FuzzyDFA) Delta(s *FuzzyStack, f func(int, int, *Concept)) bool {
+	if len(s.stack) == 0 {
+		return false
+	}
+	top := s.pop()
+	s.delta(top)
+	if data, final := d.dfa.Final(top.state); final {
+		id, _ := decodeID(data)
 		c, ok := d.graph.FindByID(id)
 		if !ok {
 			panic(fmt.Sprintf("invalid id: %d", id))
 		}
-		f(k, pos, c)
+		f(top.lev, top.next, c)
+	}
+	return true
+}
+
+type fuzzyState struct {
+	state     sparsetable.State
+	next, lev int
+}
+
+// FuzzyStack keeps track of the active states during the approximate search.
+type FuzzyStack struct {
+	stack []fuzzyState
+	d     FuzzyDFA
+	str   string
+}
+
+func (s *FuzzyStack) pop() fuzzyState {
+	top := s.stack[len(s.stack)-1]
+	s.stack = s.stack[:len(s.stack)-1]
+	return top
+}
+
+// push pushes the given state and all states that can
+// be reached by deleting characters of the labels.
+func (s *FuzzyStack) push(f fuzzyState) {
+	if f.lev > s.d.k || f.next > len(s.str) || !f.state.Valid() {
+		return
+	}
+	s.d.dfa.EachUTF8Transition(f.state, func(_ rune, t sparsetable.State) {
+		s.push(fuzzyState{state: t, next: f.next, lev: f.lev + s.d.costs.Delete})
+	})
+	s.stack = append(s.stack, f)
+}
+
+func (s *FuzzyStack) delta(f fuzzyState) {
+	if f.next >= len(s.str) {
+		return
+	}
+	// the next character of the string
+	_, n := utf8.DecodeRuneInString(s.str[f.next:])
+	// substitutions
+	s.d.dfa.EachUTF8Transition(f.state, func(_ rune, t sparsetable.State) {
+		s.push(fuzzyState{state: t, next: f.next + n, lev: f.lev + s.d.costs.Substitute})
 	})
+	// matches
+	t := f.state
+	for i := f.next; i < f.next+n && t.Valid(); i++ {
+		t = s.d.dfa.Delta(t, s.str[i])
+	}
+	s.push(fuzzyState{state: t, next: f.next + n, lev: f.lev})
+	// insertions
+	s.push(fuzzyState{state: f.state, next: f.next + n, lev: f.lev + s.d.costs.Insert})
+	// confusions
+	for _, c := range s.d.costs.Confusions {
+		if !strings.HasPrefix(s.str[f.next:], c.Text) {
+			continue
+		}
+		t := f.state
+		for i := 0; i < len(c.Label) && t.Valid(); i++ {
+			t = s.d.dfa.Delta(t, c.Label[i])
+		}
+		s.push(fuzzyState{state: t, next: f.next + len(c.Text), lev: f.lev + c.Cost})
+	}
 }
@@ -1,5 +1,7 @@
 package semix
 
+import "unicode/utf8"
+
 // FuzzyDFAMatcher uses a FuzzyDFA to search for matches in a string.
 type FuzzyDFAMatcher struct {
 	DFA FuzzyDFA
@@ -7,18 +9,22 @@ type FuzzyDFAMatcher struct {
 
 // Match returns the MatchPos of the first encountered entry in the DFA.
 // The MatchPos denotes the first encountered concept in the string or nil
-// nothing could be matched.
+// nothing could be matched. The errors of the matches are their
+// weighted edit costs (see EditCosts).
 func (m FuzzyDFAMatcher) Match(str string) MatchPos {
+	unit := m.DFA.costs.unit()
 	for i := 0; i < len(str); {
 		s := m.DFA.Initial(str[i:])
 		var savepos int
 		set := &matchset{m: make(map[*Concept]fuzzypos)}
 		for m.DFA.Delta(s, func(k, pos int, c *Concept) {
-			// skip garbage matches
-			if c == nil || isGarbage(k, i, i+pos) {
+			// skip garbage matches using the approximate number of edits
+			if c == nil || isGarbage((k+unit-1)/unit, str[i:i+pos]) {
 				return
 			}
-			pos--
+			// pos is the position after the last character
+			_, n := utf8.DecodeLastRuneInString(str[i : i+pos])
+			pos -= n
 			isws := str[i+pos] == ' '
 			if savepos == 0 && isws {
 				savepos = pos
@@ -109,10 +115,10 @@ func selectFuzzypos(o, n fuzzypos) fuzzypos {
 	return o
 }
 
-func isGarbage(k, start, end int) bool {
-	// res := (end - start) < 6
-	// return res
-	len := end - start - 2 // we do not care if start < end
+// isGarbage returns true if the match str (including the
+// surrounding whitespace) has less than 3*k characters.
+func isGarbage(k int, str string) bool {
+	len := utf8.RuneCountInString(str) - 2 // we do not care if len < 0
 	res := len < 3*k
 	return res
 }
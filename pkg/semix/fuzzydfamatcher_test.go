@@ -2,8 +2,11 @@ package semix
 
 import (
 	"fmt"
+	"math/rand"
 	"sort"
+	"strings"
 	"testing"
+	"unicode/utf8"
 )
 
 func TestFuzzyDFAMatcher(t *testing.T) {
@@ -67,7 +70,7 @@ func makeFuzzyDFAMatcher(t *testing.T, k int) FuzzyDFAMatcher {
 func fuzzyConceptToString(t *testing.T, m MatchPos, a bool) string {
 	t.Helper()
 	if m.Concept == nil {
-		return fmt.Sprintf("%v", m)
+		return fmt.Sprintf("{<nil> %d %d}", m.Begin, m.End)
 	}
 	if aa := m.Concept.Ambig(); aa != a {
 		t.Errorf("expected concept.Ambiguous()=%t; got %t", a, aa)
@@ -77,3 +80,76 @@ func fuzzyConceptToString(t *testing.T, m MatchPos, a bool) string {
 	})
 	return fmt.Sprintf("{%s %v %d %d}", m.Concept.ShortURL(), m.Concept.edges, m.Begin, m.End)
 }
+
+func TestUTF8FuzzyDFAMatcher(t *testing.T) {
+	graph := NewGraph()
+	c, _, _ := graph.Add("label", "x", "y")
+	dfa := NewDFA(Dictionary{
+		"müller":     c.ID(),
+		"straße":     c.ID(),
+		"café crème": c.ID(),
+		"bouillon":   c.ID(),
+	}, graph)
+	tests := []struct {
+		test  string
+		k, l  int
+		match bool
+	}{
+		{" müller ", 0, 0, true},
+		{" muller ", 1, 1, true},
+		{" mueller ", 1, 0, false},
+		{" mueller ", 2, 2, true},
+		{" strasse ", 1, 0, false},
+		{" strasse ", 2, 2, true},
+		{" strase ", 1, 1, true},
+		{" cafe creme ", 2, 2, true},
+		{" cafe creme ", 1, 0, false},
+		{" bouillön ", 1, 1, true},
+		{" bouillöön ", 2, 2, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.test, func(t *testing.T) {
+			m := FuzzyDFAMatcher{DFA: NewFuzzyDFA(tc.k, dfa)}
+			pos := m.Match(tc.test)
+			if match := pos.Concept != nil; match != tc.match {
+				t.Fatalf("expected match=%t; got %t", tc.match, match)
+			}
+			if pos.Concept == nil {
+				return
+			}
+			if got := tc.test[pos.Begin:pos.End]; got != strings.TrimSpace(tc.test) {
+				t.Fatalf("expected %q; got %q", strings.TrimSpace(tc.test), got)
+			}
+			var l int
+			if pos.Concept.Ambig() {
+				l = pos.Concept.EdgeAt(0).L
+			}
+			if l != tc.l {
+				t.Fatalf("expected error %d; got %d", tc.l, l)
+			}
+		})
+	}
+}
+
+func TestUTF8FuzzyDFAMatcherBoundaries(t *testing.T) {
+	graph := NewGraph()
+	c, _, _ := graph.Add("label", "x", "y")
+	dfa := NewDFA(Dictionary{"éàü": c.ID(), "aéb": c.ID(), "öäöä": c.ID()}, graph)
+	m := FuzzyDFAMatcher{DFA: NewFuzzyDFA(2, dfa)}
+	r := rand.New(rand.NewSource(42))
+	chars := []rune("aébàüöä ")
+	for i := 0; i < 500; i++ {
+		rs := make([]rune, 1+r.Intn(10))
+		for j := range rs {
+			rs[j] = chars[r.Intn(len(chars))]
+		}
+		str := " " + string(rs) + " "
+		pos := m.Match(str)
+		if pos.Concept == nil {
+			continue
+		}
+		if !utf8.ValidString(str[pos.Begin:pos.End]) || !utf8.RuneStart(str[pos.End]) {
+			t.Fatalf("%q: invalid match %d-%d", str, pos.Begin, pos.End)
+		}
+	}
+}
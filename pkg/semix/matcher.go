@@ -7,10 +7,12 @@ import (
 // MatchPos represents a matching position in a string.
 // Concept is the associated concept of the match. It is nil if nothing
 // can be matched. Begin and End mark the begin and end positions of the match
-// if Concept is not nil.
+// if Concept is not nil. Variant is true if the match is a generated
+// variant of a label (see WithVariants).
 type MatchPos struct {
 	Concept    *Concept
 	Begin, End int
+	Variant    bool
 }
 
 // Matcher is a simple interface for searching a concept in a string.
@@ -19,6 +21,15 @@ type Matcher interface {
 	Match(string) MatchPos
 }
 
+// AllMatcher is a matcher that can search for all
+// concepts in a string, including nested and overlapping ones.
+type AllMatcher interface {
+	Matcher
+	// MatchAll returns the MatchPos of all concepts in the given
+	// string ordered by their begin and end positions.
+	MatchAll(string) []MatchPos
+}
+
 // RegexMatcher uses a regex to search for a match in a string.
 type RegexMatcher struct {
 	Re      *regexp.Regexp
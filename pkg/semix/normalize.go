@@ -2,7 +2,10 @@ package semix
 
 import (
 	"regexp"
+	"sort"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
 // NormalizeString normalizes a given string.
@@ -20,3 +23,229 @@ func NormalizeString(str string, sourround bool) string {
 }
 
 var normalizeRegexp = regexp.MustCompile(`[\s\pP\pS\pZ]+`)
+
+// OffsetMap maps the byte offsets of a normalized string
+// back to the byte offsets of the original string.
+type OffsetMap struct {
+	anchors []anchor
+}
+
+// anchor marks the start of a segment of the normalized string.
+// Verbatim segments are copied from the original string. All other
+// segments replace the olen bytes of the original string starting
+// at orig: either a sequence of punctuation or whitespace characters
+// that is replaced by one whitespace or characters that were changed
+// by a Normalizer.
+type anchor struct {
+	norm, orig, olen int
+	verbatim         bool
+}
+
+// NormalizeStringWithOffsets normalizes a given string like
+// NormalizeString and returns the offset map of the normalization.
+func NormalizeStringWithOffsets(str string, sourround bool) (string, *OffsetMap) {
+	return Normalizer{}.NormalizeStringWithOffsets(str, sourround)
+}
+
+// normWriter normalizes a string that is written in consecutive
+// parts. The normalization of the concatenated parts is the same
+// as the normalization of the whole string. Offsets are global
+// to the whole string.
+type normWriter struct {
+	b       strings.Builder
+	m       *OffsetMap
+	t       *transformer // nil for the default normalization
+	norm    int          // normalized offset of the next written byte
+	end     int          // original end offset of the last segment
+	sep     bool         // a separator was read after the last segment
+	started bool         // a segment was written
+}
+
+func newNormWriter(norm, orig int, t *transformer) *normWriter {
+	return &normWriter{m: new(OffsetMap), t: t, norm: norm, end: orig}
+}
+
+// pad writes a padding whitespace that maps to the given original offset.
+func (n *normWriter) pad(orig int) {
+	n.m.add(anchor{norm: n.norm, orig: orig})
+	n.b.WriteByte(' ')
+	n.norm++
+}
+
+// write normalizes the given part that starts at the original offset ofs.
+func (n *normWriter) write(str string, ofs int) {
+	var prev int
+	for _, run := range normalizeRegexp.FindAllStringIndex(str, -1) {
+		n.segment(str[prev:run[0]], ofs+prev)
+		n.sep = true
+		prev = run[1]
+	}
+	n.segment(str[prev:], ofs+prev)
+}
+
+// segment writes a segment without any punctuation or whitespace.
+// If the normalization changes some characters of the segment, the
+// segment is split into clusters of one character followed by its
+// combining marks. Unchanged clusters are copied verbatim.
+func (n *normWriter) segment(str string, ofs int) {
+	if n.t == nil {
+		n.verbatim(str, ofs)
+		return
+	}
+	var prev int
+	for i := 0; i < len(str); {
+		j := nextCluster(str, i)
+		c := str[i:j]
+		if t := n.t.transform(c); t != c {
+			n.verbatim(str[prev:i], ofs+prev)
+			n.transformed(t, ofs+i, len(c))
+			prev = j
+		}
+		i = j
+	}
+	n.verbatim(str[prev:], ofs+prev)
+}
+
+func (n *normWriter) verbatim(str string, ofs int) {
+	if str == "" {
+		return
+	}
+	n.separate()
+	n.m.add(anchor{norm: n.norm, orig: ofs, verbatim: true})
+	n.b.WriteString(str)
+	n.norm += len(str)
+	n.end = ofs + len(str)
+}
+
+// transformed writes the transformation of the olen bytes at orig.
+// Transformations can contain punctuation or whitespace characters
+// as well.
+func (n *normWriter) transformed(str string, orig, olen int) {
+	var prev int
+	for _, run := range append(normalizeRegexp.FindAllStringIndex(str, -1), []int{len(str), len(str)}) {
+		if run[0] > prev {
+			n.separate()
+			n.m.add(anchor{norm: n.norm, orig: orig, olen: olen})
+			n.b.WriteString(str[prev:run[0]])
+			n.norm += run[0] - prev
+			n.end = orig + olen
+		}
+		if run[1] > run[0] {
+			n.sep = true
+		}
+		prev = run[1]
+	}
+}
+
+// separate writes a separating whitespace if a separator
+// was read between the last and the current segment.
+func (n *normWriter) separate() {
+	if n.sep && n.started {
+		n.pad(n.end)
+	}
+	n.sep, n.started = false, true
+}
+
+// flush returns the normalized string and the offset map
+// of all parts written since the last call to flush.
+func (n *normWriter) flush() (string, *OffsetMap) {
+	str, m := n.b.String(), n.m
+	n.b.Reset()
+	n.m = new(OffsetMap)
+	return str, m
+}
+
+// nextCluster returns the end of the character starting
+// at position i and its following combining marks.
+func nextCluster(str string, i int) int {
+	_, size := utf8.DecodeRuneInString(str[i:])
+	for i += size; i < len(str); i += size {
+		var r rune
+		r, size = utf8.DecodeRuneInString(str[i:])
+		if !unicode.Is(unicode.M, r) {
+			break
+		}
+	}
+	return i
+}
+
+// lastCluster returns the start of the last character
+// of bs and its following combining marks (see nextCluster).
+func lastCluster(bs []byte) int {
+	i := len(bs)
+	for i > 0 {
+		r, size := utf8.DecodeLastRune(bs[:i])
+		i -= size
+		if !unicode.Is(unicode.M, r) {
+			break
+		}
+	}
+	return i
+}
+
+// join returns a new offset map that maps the positions
+// of m from pos on followed by the positions of o.
+func (m *OffsetMap) join(pos int, o *OffsetMap) *OffsetMap {
+	if m == nil {
+		return o
+	}
+	j := new(OffsetMap)
+	i := sort.Search(len(m.anchors), func(i int) bool {
+		return m.anchors[i].norm > pos
+	})
+	if i > 0 {
+		i--
+	}
+	j.anchors = append(j.anchors, m.anchors[i:]...)
+	if o != nil {
+		j.anchors = append(j.anchors, o.anchors...)
+	}
+	return j
+}
+
+func (m *OffsetMap) add(a anchor) {
+	m.anchors = append(m.anchors, a)
+}
+
+// Begin maps the given start offset of the normalized
+// string to the according offset of the original string.
+func (m *OffsetMap) Begin(pos int) int {
+	a, ok := m.find(pos)
+	if !ok {
+		return pos
+	}
+	if a.verbatim {
+		return a.orig + pos - a.norm
+	}
+	return a.orig
+}
+
+// End maps the given (exclusive) end offset of the normalized
+// string to the according offset of the original string.
+func (m *OffsetMap) End(pos int) int {
+	if pos <= 0 {
+		return m.Begin(pos)
+	}
+	a, ok := m.find(pos - 1)
+	if !ok {
+		return pos
+	}
+	if a.verbatim {
+		return a.orig + pos - a.norm
+	}
+	return a.orig + a.olen
+}
+
+// find returns the anchor of the segment that contains the given position.
+func (m *OffsetMap) find(pos int) (anchor, bool) {
+	if m == nil || len(m.anchors) == 0 {
+		return anchor{}, false
+	}
+	i := sort.Search(len(m.anchors), func(i int) bool {
+		return m.anchors[i].norm > pos
+	})
+	if i == 0 {
+		return anchor{}, false
+	}
+	return m.anchors[i-1], true
+}
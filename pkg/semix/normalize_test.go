@@ -26,3 +26,35 @@ func TestNormalizeString(t *testing.T) {
 		})
 	}
 }
+
+func TestNormalizeStringWithOffsets(t *testing.T) {
+	tests := []struct {
+		test, norm             string
+		begin, end, obeg, oend int
+	}{
+		{"a, b, c", "b", 3, 4, 3, 4},
+		{"a, b, c", "a b c", 1, 6, 0, 7},
+		{"a, b, c", "b c", 3, 6, 3, 7},
+		{" (abc) ", "abc", 1, 4, 2, 5},
+		{" (abc) ", " abc ", 0, 5, 0, 5},
+		{"x -- y", " y", 2, 4, 1, 6},
+		{"Ähm, ja!", "ja", 6, 8, 6, 8},
+	}
+	for _, tc := range tests {
+		t.Run(tc.test, func(t *testing.T) {
+			str, m := NormalizeStringWithOffsets(tc.test, true)
+			if want := NormalizeString(tc.test, true); str != want {
+				t.Fatalf("expected %q; got %q", want, str)
+			}
+			if got := str[tc.begin:tc.end]; got != tc.norm {
+				t.Fatalf("expected %q; got %q", tc.norm, got)
+			}
+			if got := m.Begin(tc.begin); got != tc.obeg {
+				t.Fatalf("expected begin %d; got %d", tc.obeg, got)
+			}
+			if got := m.End(tc.end); got != tc.oend {
+				t.Fatalf("expected end %d; got %d", tc.oend, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package semix
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Normalizer defines additional normalization steps. The same
+// normalizer must be used to build the dictionary of a resource
+// (see WithNormalizer) and to normalize the input documents
+// (see Normalizer.Normalize). The zero value of a Normalizer
+// applies no additional steps.
+//
+// The steps are applied in the order Mappings, NFKC, Fold and
+// StripDiacritics on each character including its combining marks.
+type Normalizer struct {
+	// Mappings maps single characters to their replacements,
+	// e.g. the long s ſ to s or the ligature æ to ae.
+	// Punctuation and whitespace characters are always
+	// replaced by one whitespace and cannot be mapped.
+	Mappings map[string]string
+	// NFKC applies the unicode compatibility normalization NFKC.
+	NFKC bool
+	// Fold applies unicode case folding.
+	Fold bool
+	// StripDiacritics removes all diacritical marks.
+	StripDiacritics bool
+}
+
+// Check returns an error if the mappings of the normalizer are invalid.
+func (n Normalizer) Check() error {
+	for k := range n.Mappings {
+		if utf8.RuneCountInString(k) != 1 {
+			return fmt.Errorf("invalid mapping %q: not a single character", k)
+		}
+		if normalizeRegexp.MatchString(k) {
+			return fmt.Errorf("invalid mapping %q: punctuation or whitespace", k)
+		}
+	}
+	return nil
+}
+
+// Equal returns true if both normalizers apply the same steps.
+func (n Normalizer) Equal(o Normalizer) bool {
+	if n.NFKC != o.NFKC || n.Fold != o.Fold || n.StripDiacritics != o.StripDiacritics {
+		return false
+	}
+	if len(n.Mappings) != len(o.Mappings) {
+		return false
+	}
+	for k, v := range n.Mappings {
+		if w, ok := o.Mappings[k]; !ok || v != w {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns a short description of the normalization steps.
+func (n Normalizer) String() string {
+	var steps []string
+	if len(n.Mappings) > 0 {
+		ms := make([]string, 0, len(n.Mappings))
+		for k, v := range n.Mappings {
+			ms = append(ms, k+"="+v)
+		}
+		sort.Strings(ms)
+		steps = append(steps, "map("+strings.Join(ms, ",")+")")
+	}
+	if n.NFKC {
+		steps = append(steps, "nfkc")
+	}
+	if n.Fold {
+		steps = append(steps, "fold")
+	}
+	if n.StripDiacritics {
+		steps = append(steps, "strip-diacritics")
+	}
+	if len(steps) == 0 {
+		return "default"
+	}
+	return strings.Join(steps, " ")
+}
+
+// NormalizeString normalizes a given string like the
+// function NormalizeString and applies the additional
+// normalization steps.
+func (n Normalizer) NormalizeString(str string, sourround bool) string {
+	str, _ = n.NormalizeStringWithOffsets(str, sourround)
+	return str
+}
+
+// NormalizeStringWithOffsets normalizes a given string like
+// NormalizeString and returns the offset map of the normalization.
+func (n Normalizer) NormalizeStringWithOffsets(str string, sourround bool) (string, *OffsetMap) {
+	w := newNormWriter(0, 0, n.transformer())
+	if sourround {
+		w.pad(0)
+	}
+	w.write(str, 0)
+	if sourround {
+		w.pad(w.end)
+	}
+	return w.flush()
+}
+
+// Normalize normalizes the token input like the function
+// Normalize and applies the additional normalization steps.
+func (n Normalizer) Normalize(ctx context.Context, s Stream) Stream {
+	nstream := make(chan StreamToken)
+	go func() {
+		defer close(nstream)
+		ws := make(map[string]*normWriter)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case t, ok := <-s:
+				if !ok {
+					return
+				}
+				if t.Err == nil {
+					t.Token = n.normalizeToken(ws, t.Token)
+				}
+				nstream <- t
+			}
+		}
+	}()
+	return nstream
+}
+
+func (n Normalizer) normalizeToken(ws map[string]*normWriter, t Token) Token {
+	w, ok := ws[t.Path]
+	if !ok {
+		w = newNormWriter(t.Begin, t.Begin, n.transformer())
+	}
+	begin := w.norm
+	if !ok {
+		w.pad(t.Begin)
+	}
+	w.write(t.Token, t.Begin)
+	if t.More {
+		ws[t.Path] = w
+	} else {
+		w.pad(w.end)
+		delete(ws, t.Path)
+	}
+	t.Token, t.Offsets = w.flush()
+	t.Begin = begin
+	t.End = begin + len(t.Token)
+	return t
+}
+
+// transformer applies the additional normalization steps.
+// A transformer must not be used concurrently.
+type transformer struct {
+	mappings map[string]string
+	nfkc     bool
+	fold     transform.Transformer
+	strip    transform.Transformer
+}
+
+// transformer returns a new transformer or
+// nil if there are no additional steps.
+func (n Normalizer) transformer() *transformer {
+	if len(n.Mappings) == 0 && !n.NFKC && !n.Fold && !n.StripDiacritics {
+		return nil
+	}
+	t := &transformer{mappings: n.Mappings, nfkc: n.NFKC}
+	if n.Fold {
+		t.fold = cases.Fold()
+	}
+	if n.StripDiacritics {
+		t.strip = transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	}
+	return t
+}
+
+func (t *transformer) transform(str string) string {
+	if len(t.mappings) > 0 {
+		var b strings.Builder
+		for _, r := range str {
+			if m, ok := t.mappings[string(r)]; ok {
+				b.WriteString(m)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+		str = b.String()
+	}
+	if t.nfkc {
+		str = norm.NFKC.String(str)
+	}
+	if t.fold != nil {
+		str, _, _ = transform.String(t.fold, str)
+	}
+	if t.strip != nil {
+		str, _, _ = transform.String(t.strip, str)
+	}
+	return str
+}
@@ -0,0 +1,127 @@
+package semix
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestNormalizer(t *testing.T) {
+	tests := []struct {
+		n          Normalizer
+		test, want string
+	}{
+		{Normalizer{}, "Äpfel, Birnen", " Äpfel Birnen "},
+		{Normalizer{Fold: true}, "Äpfel, BIRNEN", " äpfel birnen "},
+		{Normalizer{Fold: true}, "Straße", " strasse "},
+		{Normalizer{NFKC: true}, "ﬁsch ①", " fisch 1 "},
+		{Normalizer{StripDiacritics: true}, "Äpfel, Crème", " Apfel Creme "},
+		{Normalizer{StripDiacritics: true}, "Äpfel", " Apfel "},
+		{Normalizer{Mappings: map[string]string{"ſ": "s", "æ": "ae"}}, "Weiſe Cæsar", " Weise Caesar "},
+		{Normalizer{Mappings: map[string]string{"ß": "-"}}, "Aßb", " A b "},
+		{Normalizer{NFKC: true, Fold: true, StripDiacritics: true}, "ÆRGER, Café", " ærger cafe "},
+	}
+	for _, tc := range tests {
+		t.Run(tc.n.String()+" "+tc.test, func(t *testing.T) {
+			if got := tc.n.NormalizeString(tc.test, true); got != tc.want {
+				t.Fatalf("expected %q; got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestNormalizerOffsets(t *testing.T) {
+	n := Normalizer{Fold: true, StripDiacritics: true}
+	tests := []struct {
+		test, word, want string
+	}{
+		{"Die Straße", "strasse", "Straße"},
+		{"über, Äpfel!", "apfel", "Äpfel"},
+		{"ÜBER Äpfel", "uber", "ÜBER"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.test, func(t *testing.T) {
+			str, m := n.NormalizeStringWithOffsets(tc.test, true)
+			i := strings.Index(str, tc.word)
+			if i < 0 {
+				t.Fatalf("cannot find %q in %q", tc.word, str)
+			}
+			begin, end := m.Begin(i), m.End(i+len(tc.word))
+			if got := tc.test[begin:end]; got != tc.want {
+				t.Fatalf("expected %q; got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestNormalizerChunks(t *testing.T) {
+	tests := []struct {
+		n   Normalizer
+		doc string
+	}{
+		{Normalizer{Fold: true, NFKC: true}, "Eine ﬁsche GROSSE Sache, über Straßen und -- Wege. "},
+		{Normalizer{NFKC: true}, "abce\u0301f"},
+	}
+	for _, tc := range tests {
+		want := tc.n.NormalizeString(tc.doc, true)
+		for _, size := range []int{4, 5, 6, 7, 13, len(tc.doc)} {
+			ctx, cancel := context.WithCancel(context.Background())
+			var strs []string
+			for st := range tc.n.Normalize(ctx, ReadChunks(ctx, size, NewStringDocument("test", tc.doc))) {
+				if st.Err != nil {
+					t.Fatalf("got error: %v", st.Err)
+				}
+				strs = append(strs, st.Token.Token)
+			}
+			cancel()
+			if got := strings.Join(strs, ""); got != want {
+				t.Fatalf("%q chunk size %d: expected %q; got %q", tc.doc, size, want, got)
+			}
+		}
+	}
+}
+
+func TestNormalizerString(t *testing.T) {
+	tests := []struct {
+		n    Normalizer
+		want string
+	}{
+		{Normalizer{}, "default"},
+		{Normalizer{Fold: true, NFKC: true}, "nfkc fold"},
+		{Normalizer{Mappings: map[string]string{"ſ": "s", "æ": "ae"}, StripDiacritics: true},
+			"map(æ=ae,ſ=s) strip-diacritics"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.want, func(t *testing.T) {
+			if got := tc.n.String(); got != tc.want {
+				t.Fatalf("expected %q; got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestParseWithNormalizer(t *testing.T) {
+	n := Normalizer{Fold: true, Mappings: map[string]string{"ſ": "s"}}
+	r, err := Parse(newTestParser(
+		"A", "p", "B",
+		"A", "d", "Groſse Namen",
+		"B", "d", "KLEINE-namen",
+	), testTraits{}, WithNormalizer(n))
+	if err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	for _, entry := range []string{"grosse namen", "kleine namen"} {
+		if _, ok := r.Dictionary[entry]; !ok {
+			t.Fatalf("cannot find %q in dictionary", entry)
+		}
+	}
+	if !r.Normalizer.Equal(n) {
+		t.Fatalf("expected normalizer %s; got %s", n, r.Normalizer)
+	}
+	for _, m := range []map[string]string{{"ab": "c"}, {"-": ""}} {
+		if _, err := Parse(newTestParser(), testTraits{},
+			WithNormalizer(Normalizer{Mappings: m})); err == nil {
+			t.Fatalf("expected error for mapping %v", m)
+		}
+	}
+}
@@ -10,9 +10,43 @@ type Parser interface {
 	Parse(func(string, string, string) error) error
 }
 
+// ParseOption defines an option for Parse.
+type ParseOption func(*parser)
+
+// WithNormalizer sets the normalizer that is used
+// to normalize the entries of the dictionary.
+func WithNormalizer(n Normalizer) ParseOption {
+	return func(p *parser) {
+		p.normalizer = n
+	}
+}
+
+// VariantGenerator generates variants of dictionary labels,
+// e.g. inflected forms. String returns a description of the
+// generator that is recorded in the resource.
+type VariantGenerator interface {
+	Variants(string) []string
+	String() string
+}
+
+// WithVariants sets the generator for the variants of the labels.
+// Variants never override labels. Variants of different concepts
+// that collide are discarded.
+func WithVariants(g VariantGenerator) ParseOption {
+	return func(p *parser) {
+		p.variants = g
+	}
+}
+
 // Parse creates a resource from a parser.
-func Parse(p Parser, t Traits) (*Resource, error) {
+func Parse(p Parser, t Traits, opts ...ParseOption) (*Resource, error) {
 	parser := newParser(t)
+	for _, opt := range opts {
+		opt(parser)
+	}
+	if err := parser.normalizer.Check(); err != nil {
+		return nil, errors.Wrapf(err, "invalid normalizer")
+	}
 	if err := p.Parse(parser.add); err != nil {
 		return nil, err
 	}
@@ -31,6 +65,9 @@ type parser struct {
 	ambigs     map[string][]string
 	rules      RulesDictionary
 	traits     Traits
+	normalizer Normalizer
+	variants   VariantGenerator
+	generated  map[string]map[string]bool
 }
 
 func newParser(traits Traits) *parser {
@@ -40,6 +77,7 @@ func newParser(traits Traits) *parser {
 		labels:     make(map[string]label),
 		ambigs:     make(map[string][]string),
 		rules:      make(RulesDictionary),
+		generated:  make(map[string]map[string]bool),
 		traits:     traits,
 	}
 }
@@ -50,7 +88,13 @@ func (parser *parser) parse() (*Resource, error) {
 	if err != nil {
 		return nil, errors.Wrapf(err, "cannot build dictionary")
 	}
-	return NewResource(g, d, parser.rules), nil
+	v := parser.buildVariants(d)
+	r := newResource(g, d, v, parser.rules)
+	r.Normalizer = parser.normalizer
+	if parser.variants != nil {
+		r.VariantRules = parser.variants.String()
+	}
+	return r, nil
 }
 
 func (parser *parser) buildGraph() *Graph {
@@ -110,6 +154,37 @@ func (parser *parser) buildDictionary(g *Graph) (Dictionary, error) {
 	return d, nil
 }
 
+// buildVariants maps the generated variants to the dictionary entries
+// of their labels. Variants of labels of different entries conflict
+// and are skipped.
+func (parser *parser) buildVariants(d Dictionary) Dictionary {
+	v := make(Dictionary)
+	for entry, labels := range parser.generated {
+		if _, ok := d[entry]; ok {
+			continue
+		}
+		if _, ok := parser.ambigs[entry]; ok {
+			continue
+		}
+		var id int32
+		for l := range labels {
+			lid, ok := d[l]
+			if !ok {
+				continue
+			}
+			if id != 0 && id != lid {
+				id = 0
+				break
+			}
+			id = lid
+		}
+		if id != 0 {
+			v[entry] = id
+		}
+	}
+	return v
+}
+
 func (parser *parser) add(s, p, o string) error {
 	if parser.traits.Ignore(p) {
 		return nil
@@ -155,7 +230,8 @@ func (parser *parser) addLabels(entry, url string, ambig bool) error {
 		return errors.Wrapf(err, "cannot expand %s", entry)
 	}
 	for _, expanded := range labels {
-		normalized := NormalizeString(expanded, false)
+		normalized := parser.normalizer.NormalizeString(expanded, false)
+		parser.addVariants(expanded, normalized)
 		if _, ok := parser.ambigs[normalized]; ok {
 			parser.ambigs[normalized] = append(parser.ambigs[normalized], url)
 			return nil
@@ -170,3 +246,19 @@ func (parser *parser) addLabels(entry, url string, ambig bool) error {
 	}
 	return nil
 }
+
+// addVariants records the variants of the given entry together
+// with its normalized label. Variants are mapped to the concepts
+// of their labels after all ambiguities have been handled.
+func (parser *parser) addVariants(entry, label string) {
+	if parser.variants == nil {
+		return
+	}
+	for _, v := range parser.variants.Variants(entry) {
+		normalized := parser.normalizer.NormalizeString(v, false)
+		if parser.generated[normalized] == nil {
+			parser.generated[normalized] = make(map[string]bool)
+		}
+		parser.generated[normalized][label] = true
+	}
+}
@@ -135,3 +135,67 @@ func (testTraits) IsTransitive(p string) bool     { return p == "t" }
 func (testTraits) IsInverted(p string) bool       { return p == "v" }
 func (testTraits) IsRule(p string) bool           { return p == "r" }
 func (testTraits) HandleAmbigs() HandleAmbigsFunc { return HandleAmbigsWithSplit }
+
+type testVariants map[string][]string
+
+func (v testVariants) Variants(label string) []string { return v[label] }
+func (v testVariants) String() string                 { return "test" }
+
+func TestParseWithVariants(t *testing.T) {
+	r, err := Parse(newTestParser(
+		"A", "p", "B",
+		"C", "p", "D",
+		"E", "p", "D",
+		"A", "d", "door",
+		"B", "d", "window",
+		"C", "d", "leaf",
+		"D", "d", "leave",
+		"E", "d", "windows",
+		"F", "p", "D",
+		"G", "p", "D",
+		"F", "d", "bank",
+		"G", "d", "bank",
+	), testTraits{}, WithVariants(testVariants{
+		"bank":   {"banks"},
+		"door":   {"doors"},
+		"window": {"windows"},
+		"leaf":   {"leaves"},
+		"leave":  {"leaves"},
+	}))
+	if err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	if got := r.VariantRules; got != "test" {
+		t.Fatalf("expected variant rules %q; got %q", "test", got)
+	}
+	// windows is an original label and leaves is a conflicting variant
+	if got := len(r.Variants); got != 2 {
+		t.Fatalf("expected 2 variants; got %d", got)
+	}
+	if _, ok := r.Dictionary["doors"]; ok {
+		t.Fatalf("found variant %q in dictionary", "doors")
+	}
+	tests := []struct {
+		test, want string
+		variant    bool
+	}{
+		{" open door ", "A", false},
+		{" open doors ", "A", true},
+		{" open windows ", "E", false},
+		{" open window ", "B", false},
+		{" red leaves ", "", false},
+		{" river banks ", "F-G", true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.test, func(t *testing.T) {
+			m := DFAMatcher{DFA: r.DFA}.Match(tc.test)
+			var got string
+			if m.Concept != nil {
+				got = m.Concept.URL()
+			}
+			if got != tc.want || m.Variant != tc.variant {
+				t.Fatalf("expected %s (%t); got %s (%t)", tc.want, tc.variant, got, m.Variant)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package semix
 import (
 	"bytes"
 	"encoding/gob"
+	"io"
 )
 
 // Dictionary is a dictionary that maps the labels of the concepts
@@ -15,20 +16,35 @@ type Dictionary map[string]int32
 type RulesDictionary map[string]string
 
 // Resource is a struct that holds all parsed knwoledge base resources.
+// Normalizer is the normalizer that was used to build the dictionary.
+// Input documents must be normalized with the same normalizer.
+// Variants holds the generated variants of the labels and VariantRules
+// describes the generator of the variants (see WithVariants).
+// AhoCorasick holds the same entries as DFA.
 type Resource struct {
-	Graph      *Graph
-	Dictionary Dictionary
-	Rules      RulesDictionary
-	DFA        DFA
+	Graph        *Graph
+	Dictionary   Dictionary
+	Rules        RulesDictionary
+	DFA          DFA
+	Normalizer   Normalizer
+	Variants     Dictionary
+	VariantRules string
+	AhoCorasick  AhoCorasick
 }
 
 // NewResource creates a new resource.
 func NewResource(g *Graph, d Dictionary, r RulesDictionary) *Resource {
+	return newResource(g, d, nil, r)
+}
+
+func newResource(g *Graph, d, v Dictionary, r RulesDictionary) *Resource {
 	return &Resource{
-		Graph:      g,
-		Dictionary: d,
-		Rules:      r,
-		DFA:        NewDFA(d, g),
+		Graph:       g,
+		Dictionary:  d,
+		Rules:       r,
+		DFA:         newDFA(d, v, g),
+		Variants:    v,
+		AhoCorasick: newAhoCorasick(d, v, g),
 	}
 }
 
@@ -68,9 +84,42 @@ func (r *Resource) GobDecode(bs []byte) error {
 		}
 	}
 	r.DFA.graph = r.Graph
+	if err := r.decodeOptional(decoder); err != nil {
+		return err
+	}
+	if r.AhoCorasick.ac == nil {
+		r.AhoCorasick = newAhoCorasick(r.Dictionary, r.Variants, r.Graph)
+	}
+	r.AhoCorasick.graph = r.Graph
+	return nil
+}
+
+// decodeOptional decodes the fields that older resources do not
+// record: the normalizer, the variants and the Aho-Corasick automaton.
+func (r *Resource) decodeOptional(decoder *gob.Decoder) error {
+	if err := decoder.Decode(&r.Normalizer); err != nil {
+		return eof(err)
+	}
+	if err := decoder.Decode(&r.Variants); err != nil {
+		return eof(err)
+	}
+	if err := decoder.Decode(&r.VariantRules); err != nil {
+		return eof(err)
+	}
+	if err := decoder.Decode(&r.AhoCorasick); err != nil {
+		return eof(err)
+	}
 	return nil
 }
 
+// eof returns nil if err is io.EOF.
+func eof(err error) error {
+	if err == io.EOF {
+		return nil
+	}
+	return err
+}
+
 // GobEncode encodes a graph to gob encoded binary data.
 func (r *Resource) GobEncode() ([]byte, error) {
 	buffer := new(bytes.Buffer)
@@ -96,6 +145,18 @@ func (r *Resource) GobEncode() ([]byte, error) {
 	if err := encoder.Encode(register); err != nil {
 		return nil, err
 	}
+	if err := encoder.Encode(r.Normalizer); err != nil {
+		return nil, err
+	}
+	if err := encoder.Encode(r.Variants); err != nil {
+		return nil, err
+	}
+	if err := encoder.Encode(r.VariantRules); err != nil {
+		return nil, err
+	}
+	if err := encoder.Encode(r.AhoCorasick); err != nil {
+		return nil, err
+	}
 	return buffer.Bytes(), nil
 }
 
@@ -4,24 +4,45 @@ import (
 	"bytes"
 	"encoding/gob"
 	"reflect"
+	"strings"
 	"testing"
 )
 
 func TestResourceGOB(t *testing.T) {
-	r, err := Parse(makeNewTestParser(), testTraits{})
-	if err != nil {
-		t.Fatalf("got error: %s", err)
+	tests := []struct {
+		name string
+		opts []ParseOption
+	}{
+		{"default", nil},
+		{"normalizer", []ParseOption{
+			WithNormalizer(Normalizer{Fold: true, Mappings: map[string]string{"ſ": "s"}}),
+		}},
+		{"variants", []ParseOption{
+			WithVariants(testVariants{"distinct": {"distincts"}}),
+		}},
 	}
-	b := new(bytes.Buffer)
-	if err := gob.NewEncoder(b).Encode(r); err != nil {
-		t.Fatalf("got error: %s", err)
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := Parse(makeNewTestParser(), testTraits{}, tc.opts...)
+			if err != nil {
+				t.Fatalf("got error: %s", err)
+			}
+			b := new(bytes.Buffer)
+			if err := gob.NewEncoder(b).Encode(r); err != nil {
+				t.Fatalf("got error: %s", err)
+			}
+			x := new(Resource)
+			if err := gob.NewDecoder(b).Decode(x); err != nil {
+				t.Fatalf("got error: %s", err)
+			}
+			if !reflect.DeepEqual(x, r) {
+				t.Fatalf("decoded resources do not equal encoded resources")
+			}
+			str := " " + strings.Join([]string{"name", "distinct", "distincts", "ambiguous"}, " ") + " "
+			want := DFAMatcher{DFA: r.DFA}.MatchAll(str)
+			if got := (AhoCorasickMatcher{AC: x.AhoCorasick}).MatchAll(str); !reflect.DeepEqual(got, want) {
+				t.Fatalf("expected %v; got %v", want, got)
+			}
+		})
 	}
-	x := new(Resource)
-	if err := gob.NewDecoder(b).Decode(x); err != nil {
-		t.Fatalf("got error: %s", err)
-	}
-	if !reflect.DeepEqual(*x, *r) {
-		t.Fatalf("decoded resources do not equal encoded resources")
-	}
-
 }
@@ -1,8 +1,11 @@
 package semix
 
 import (
+	"bufio"
 	"context"
-	"sync"
+	"io"
+	"strings"
+	"unicode/utf8"
 )
 
 // StreamToken Wraps either a token or an error
@@ -18,48 +21,77 @@ type Stream <-chan StreamToken
 // It prepends and appends one ' ' character to the token.
 // All sequences of one or more unicode punctuation or unicode whitespaces
 // are replaced by exactly one whitespace character ' '.
+// The offset map of the normalization is stored in the token.
+//
+// The chunks of a document (see Read) are normalized as if the
+// whole document was normalized at once: the padding is only added
+// to the first and last chunk and the positions of the normalized
+// chunks are global to the normalized document.
 func Normalize(ctx context.Context, s Stream) Stream {
-	nstream := make(chan StreamToken)
-	go func() {
-		defer close(nstream)
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			case t, ok := <-s:
-				if !ok {
-					return
-				}
-				if t.Err == nil {
-					t.Token.Token = NormalizeString(t.Token.Token, true)
-					t.Token.End = t.Token.Begin + len(t.Token.Token)
-				}
-				nstream <- t
-			}
-		}
-	}()
-	return nstream
+	return Normalizer{}.Normalize(ctx, s)
 }
 
 // Match matches concepts in the stream and splits the tokens accordingly.
 // So one token ' text <match> text ' is split into ' text ',
 // '<match>' and ' text '.
+//
+// If a token is followed by more chunks of the same document,
+// its last ChunkOverlap bytes are carried over and matched together
+// with the next chunk. So matches that span chunk boundaries are found
+// exactly once, if they are not longer than ChunkOverlap. The unmatched
+// tokens of a chunk keep its More flag, so that subsequent matchers
+// carry them over as well.
 func Match(ctx context.Context, m Matcher, s Stream) Stream {
+	return match(ctx, m, nil, s)
+}
+
+// MatchAll matches concepts in the stream like Match. Additionally,
+// all other matches that begin within a maximal match are put into
+// the stream after the maximal match. These nested or overlapping
+// matches are marked as Nested. So for a token ' university of new york '
+// the maximal match 'university of new york' is followed by the nested
+// match 'new york'.
+func MatchAll(ctx context.Context, m AllMatcher, s Stream) Stream {
+	return match(ctx, m, m, s)
+}
+
+func match(ctx context.Context, m Matcher, all AllMatcher, s Stream) Stream {
 	ms := make(chan StreamToken, 2) // matcher will put 2 token into the stream.
 	go func() {
 		defer close(ms)
+		carry := make(map[string]Token)
 		for {
 			select {
 			case <-ctx.Done():
 				return
 			case t, ok := <-s:
 				if !ok {
+					flushCarry(ctx, ms, carry, m, all, func(Token) bool { return true })
 					return
 				}
+				// Earlier matching stages split their input at the chunk
+				// cuts as well. So the carry is only joined with unmatched
+				// tokens that directly follow it; it is flushed otherwise.
+				flushCarry(ctx, ms, carry, m, all, func(c Token) bool {
+					return c.Path != t.Token.Path || t.Err != nil ||
+						t.Token.Concept != nil || c.End != t.Token.Begin
+				})
 				if t.Err != nil || t.Token.Concept != nil {
 					ms <- t
-				} else {
-					doMatch(ctx, ms, t.Token, m)
+					continue
+				}
+				if c, ok := carry[t.Token.Path]; ok {
+					t.Token = joinTokens(c, t.Token)
+					delete(carry, t.Token.Path)
+				}
+				// Small chunks are collected before they are matched, so
+				// that the carried tail is not matched again and again.
+				if t.Token.More && len(t.Token.Token) < 2*ChunkOverlap {
+					carry[t.Token.Path] = t.Token
+					continue
+				}
+				if c, ok := doMatch(ctx, ms, t.Token, m, all); ok {
+					carry[t.Token.Path] = c
 				}
 			}
 		}
@@ -67,10 +99,35 @@ func Match(ctx context.Context, m Matcher, s Stream) Stream {
 	return ms
 }
 
-func doMatch(ctx context.Context, s chan StreamToken, t Token, m Matcher) {
+// flushCarry matches and removes all carried tokens for which
+// the given function returns true.
+func flushCarry(ctx context.Context, s chan StreamToken, carry map[string]Token, m Matcher, all AllMatcher, f func(Token) bool) {
+	for path, c := range carry {
+		if !f(c) {
+			continue
+		}
+		delete(carry, path)
+		c.More = false
+		doMatch(ctx, s, c, m, all)
+	}
+}
+
+// doMatch matches the given token. If the token is followed by more
+// chunks, the unmatched tail of the token is not put into the stream
+// but returned. If all is not nil, the nested matches of the maximal
+// matches are put into the stream as well.
+func doMatch(ctx context.Context, s chan StreamToken, t Token, m Matcher, all AllMatcher) (Token, bool) {
 	if t.Concept != nil {
 		panic("t.Token.Concept != nil")
 	}
+	cut := len(t.Token)
+	if t.More {
+		cut = chunkCut(t.Token)
+	}
+	var nested []MatchPos
+	if all != nil {
+		nested = all.MatchAll(t.Token)
+	}
 	rest := t.Token
 	ofs := t.Begin
 	// say.Info("### MATCHING TOKEN %v", t)
@@ -83,24 +140,20 @@ func doMatch(ctx context.Context, s chan StreamToken, t Token, m Matcher) {
 		// }
 		match := m.Match(rest)
 		// say.Info("match: %v", match)
-		if match.Concept == nil {
-			putMatches(ctx, s, Token{
-				Token:   rest,
-				Path:    t.Path,
-				Begin:   ofs,
-				End:     ofs + len(rest),
-				Concept: nil,
-			})
-			rest = ""
+		if match.Concept == nil || ofs-t.Begin+match.Begin > cut {
+			break
 		} else if match.Begin == 0 {
 			// say.Info("DO_MATCH: %v", match)
 			putMatches(ctx, s, Token{
 				Token:   rest[0:match.End],
 				Path:    t.Path,
+				Offsets: t.Offsets,
 				Begin:   ofs,
 				End:     ofs + match.End,
 				Concept: match.Concept,
+				Variant: match.Variant,
 			})
+			nested = putNested(ctx, s, t, nested, ofs-t.Begin, ofs-t.Begin+match.End)
 			rest = rest[match.End:]
 			ofs += match.End
 		} else {
@@ -108,6 +161,7 @@ func doMatch(ctx context.Context, s chan StreamToken, t Token, m Matcher) {
 			putMatches(ctx, s, Token{
 				Token:   rest[0:match.Begin],
 				Path:    t.Path,
+				Offsets: t.Offsets,
 				Begin:   ofs,
 				End:     ofs + match.Begin,
 				Concept: nil,
@@ -115,14 +169,92 @@ func doMatch(ctx context.Context, s chan StreamToken, t Token, m Matcher) {
 				Token{
 					Token:   rest[match.Begin:match.End],
 					Path:    t.Path,
+					Offsets: t.Offsets,
 					Begin:   ofs + match.Begin,
 					End:     ofs + match.End,
 					Concept: match.Concept,
+					Variant: match.Variant,
 				})
+			nested = putNested(ctx, s, t, nested,
+				ofs-t.Begin+match.Begin, ofs-t.Begin+match.End)
 			rest = rest[match.End:]
 			ofs += match.End
 		}
 	}
+	if n := cut - (ofs - t.Begin); n > 0 {
+		putMatches(ctx, s, Token{
+			Token:   rest[:n],
+			Path:    t.Path,
+			Offsets: t.Offsets,
+			Begin:   ofs,
+			End:     ofs + n,
+			Concept: nil,
+			More:    t.More,
+		})
+		rest = rest[n:]
+		ofs += n
+	}
+	return Token{
+		Token:   rest,
+		Path:    t.Path,
+		Offsets: t.Offsets,
+		Begin:   ofs,
+		End:     ofs + len(rest),
+		More:    true,
+	}, t.More
+}
+
+// putNested puts the matches that begin within the maximal match
+// [b,e) of the token into the stream and returns the remaining matches.
+// The positions of the matches are relative to the token.
+func putNested(ctx context.Context, s chan StreamToken, t Token, ms []MatchPos, b, e int) []MatchPos {
+	for len(ms) > 0 && ms[0].Begin < e {
+		m := ms[0]
+		ms = ms[1:]
+		if m.Begin < b || (m.Begin == b && m.End == e) {
+			continue
+		}
+		putMatches(ctx, s, Token{
+			Token:   t.Token[m.Begin:m.End],
+			Path:    t.Path,
+			Offsets: t.Offsets,
+			Begin:   t.Begin + m.Begin,
+			End:     t.Begin + m.End,
+			Concept: m.Concept,
+			Variant: m.Variant,
+			Nested:  true,
+		})
+	}
+	return ms
+}
+
+// ChunkOverlap is the maximal number of bytes that
+// are carried over from one chunk to the next by Match.
+const ChunkOverlap = 4096
+
+// chunkCut returns the position in the given chunk from which on
+// the chunk is carried over to the next chunk. The position is the
+// last whitespace before the last ChunkOverlap bytes of the chunk.
+func chunkCut(str string) int {
+	cut := len(str) - ChunkOverlap
+	if cut <= 0 {
+		return 0
+	}
+	if i := strings.LastIndexByte(str[:cut+1], ' '); i >= 0 {
+		return i
+	}
+	for cut > 0 && !utf8.RuneStart(str[cut]) {
+		cut--
+	}
+	return cut
+}
+
+// joinTokens joins the carried over tail of a chunk with the next chunk.
+func joinTokens(c, t Token) Token {
+	t.Token = c.Token + t.Token
+	t.Offsets = c.Offsets.join(c.Begin, t.Offsets)
+	t.Begin = c.Begin
+	return t
 }
 
 func putMatches(ctx context.Context, out chan StreamToken, ts ...Token) {
@@ -136,28 +268,105 @@ func putMatches(ctx context.Context, out chan StreamToken, ts ...Token) {
 	}
 }
 
-// Read reads documents into tokens.
+// DefaultChunkSize is the default maximal size
+// of the chunks of a document in bytes.
+const DefaultChunkSize = 1 << 20
+
+// Read reads documents into tokens. The documents are read one after
+// another in chunks of at most DefaultChunkSize bytes.
 func Read(ctx context.Context, ds ...Document) Stream {
-	rstream := make(chan StreamToken, len(ds))
+	return ReadChunks(ctx, DefaultChunkSize, ds...)
+}
+
+// ReadChunks reads documents into tokens. The documents are read one
+// after another in chunks of at most n bytes, so the chunks of a document
+// are consecutive in the stream. Chunks are never split within a UTF-8
+// encoded rune or between a character and its combining marks. The
+// positions of the chunks are global to their document and all but the
+// last chunk of a document are marked with More.
+func ReadChunks(ctx context.Context, n int, ds ...Document) Stream {
+	rstream := make(chan StreamToken)
 	go func() {
-		var wg sync.WaitGroup
 		defer close(rstream)
-		wg.Add(len(ds))
 		for _, d := range ds {
-			go func(d Document) {
-				defer wg.Done()
-				select {
-				case <-ctx.Done():
-					return
-				case rstream <- ReadStreamToken(d):
-				}
-			}(d)
+			if !readChunks(ctx, rstream, d, n) {
+				return
+			}
 		}
-		wg.Wait()
 	}()
 	return rstream
 }
 
+func readChunks(ctx context.Context, s chan StreamToken, d Document, n int) bool {
+	defer func() { _ = d.Close() }()
+	if n <= 0 {
+		n = DefaultChunkSize
+	}
+	if n < utf8.UTFMax {
+		n = utf8.UTFMax
+	}
+	r := bufio.NewReader(d)
+	buf := make([]byte, n+utf8.UTFMax)
+	var ofs, pending int
+	for {
+		k, err := io.ReadFull(r, buf[pending:n])
+		more := err == nil
+		if more {
+			_, err = r.Peek(1)
+			more = err == nil
+		}
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return put(ctx, s, StreamToken{Err: err})
+		}
+		k += pending
+		cut := k
+		if more {
+			cut = runeCut(buf[:k])
+			// The next chunk could start with combining marks of
+			// the last character, so the character is carried over
+			// unless it fills the whole chunk.
+			if c := lastCluster(buf[:cut]); c > 0 {
+				cut = c
+			}
+		}
+		t := Token{
+			Token: string(buf[:cut]),
+			Path:  d.Path(),
+			Begin: ofs,
+			End:   ofs + cut,
+			More:  more,
+		}
+		if !put(ctx, s, StreamToken{Token: t}) || !more {
+			return !more
+		}
+		ofs += cut
+		pending = copy(buf, buf[cut:k])
+	}
+}
+
+// runeCut returns the length of the longest prefix
+// of bs that does not end with an incomplete rune.
+func runeCut(bs []byte) int {
+	for i := len(bs) - 1; i >= 0 && i >= len(bs)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(bs[i]) {
+			if utf8.FullRune(bs[i:]) {
+				return len(bs)
+			}
+			return i
+		}
+	}
+	return len(bs)
+}
+
+func put(ctx context.Context, s chan StreamToken, t StreamToken) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case s <- t:
+		return true
+	}
+}
+
 // ReadStreamToken reads a StreamToken from a document.
 // It simply wraps ReadToken and returns a StreamToken
 func ReadStreamToken(d Document) StreamToken {
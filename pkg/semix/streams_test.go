@@ -3,9 +3,15 @@ package semix
 import (
 	"context"
 	"errors"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sort"
 	"strings"
 	"sync"
 	"testing"
+	"unicode"
+	"unicode/utf8"
 )
 
 func TestReadStream(t *testing.T) {
@@ -70,6 +76,225 @@ func TestMatcherStream(t *testing.T) {
 	}
 }
 
+func TestOriginalOffsets(t *testing.T) {
+	tests := []struct {
+		test       string
+		begin, end int
+	}{
+		{"match", 0, 5},
+		{"A, match", 3, 8},
+		{"(A) -- match!", 7, 12},
+		{"A \t match B", 4, 9},
+	}
+	for _, tc := range tests {
+		t.Run(tc.test, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			d := NewStringDocument("test", tc.test)
+			for st := range Match(ctx, testm{}, Normalize(ctx, Read(ctx, d))) {
+				if st.Err != nil {
+					t.Fatalf("got error: %v", st.Err)
+				}
+				if st.Token.Concept == nil {
+					continue
+				}
+				begin, end := st.Token.Original()
+				if begin != tc.begin || end != tc.end {
+					t.Fatalf("expected %d-%d; got %d-%d", tc.begin, tc.end, begin, end)
+				}
+				if got := tc.test[begin:end]; got != "match" {
+					t.Fatalf("expected %q; got %q", "match", got)
+				}
+			}
+		})
+	}
+}
+
+func TestReadChunks(t *testing.T) {
+	tests := []struct {
+		test string
+		n    int
+	}{
+		{"", 3},
+		{"abc", 3},
+		{"abcdefgh", 3},
+		{"abcdefgh", 100},
+		{"äöüß€", 4},
+		{"aäöüß€b", 5},
+		{"abce\u0301f", 4},
+		{"abce\u0301\u0323f", 5},
+	}
+	for _, tc := range tests {
+		t.Run(fmt.Sprintf("%s %d", tc.test, tc.n), func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			var strs []string
+			var ofs int
+			for st := range ReadChunks(ctx, tc.n, NewStringDocument("test", tc.test)) {
+				if st.Err != nil {
+					t.Fatalf("got error: %v", st.Err)
+				}
+				if st.Token.Begin != ofs || st.Token.End != ofs+len(st.Token.Token) {
+					t.Fatalf("invalid positions: %v", st.Token)
+				}
+				if !utf8.ValidString(st.Token.Token) {
+					t.Fatalf("invalid chunk: %q", st.Token.Token)
+				}
+				if r, _ := utf8.DecodeRuneInString(st.Token.Token); unicode.Is(unicode.M, r) {
+					t.Fatalf("chunk starts with combining mark: %q", st.Token.Token)
+				}
+				if more := st.Token.End < len(tc.test); st.Token.More != more {
+					t.Fatalf("expected more=%t; got %t", more, st.Token.More)
+				}
+				ofs = st.Token.End
+				strs = append(strs, st.Token.Token)
+			}
+			if got := strings.Join(strs, ""); got != tc.test {
+				t.Fatalf("expected %q; got %q", tc.test, got)
+			}
+		})
+	}
+}
+
+func TestChunkedNormalize(t *testing.T) {
+	tests := []string{
+		"",
+		"  ",
+		"a,b,c",
+		" (abc) -- äöü; x ",
+		"Ein langer Satz, mit -- einigen Zeichen! Und noch mehr...",
+	}
+	for _, tc := range tests {
+		want := NormalizeString(tc, true)
+		for n := 4; n <= len(tc)+1; n++ {
+			t.Run(fmt.Sprintf("%s %d", tc, n), func(t *testing.T) {
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+				var strs []string
+				var ofs int
+				for st := range Normalize(ctx, ReadChunks(ctx, n, NewStringDocument("test", tc))) {
+					if st.Err != nil {
+						t.Fatalf("got error: %v", st.Err)
+					}
+					if st.Token.Begin != ofs {
+						t.Fatalf("expected begin %d; got %d", ofs, st.Token.Begin)
+					}
+					ofs = st.Token.End
+					strs = append(strs, st.Token.Token)
+				}
+				if got := strings.Join(strs, ""); got != want {
+					t.Fatalf("expected %q; got %q", want, got)
+				}
+			})
+		}
+	}
+}
+
+func TestChunkedMatch(t *testing.T) {
+	doc := strings.Repeat("A, match B; ", 2*ChunkOverlap)
+	for _, n := range []int{5, 100, ChunkOverlap + 7, 3 * ChunkOverlap, len(doc)} {
+		t.Run(fmt.Sprintf("%d", n), func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			var matches, ofs int
+			s := Match(ctx, testm{}, Normalize(ctx, ReadChunks(ctx, n, NewStringDocument("test", doc))))
+			for st := range s {
+				if st.Err != nil {
+					t.Fatalf("got error: %v", st.Err)
+				}
+				if st.Token.Begin != ofs {
+					t.Fatalf("expected begin %d; got %d", ofs, st.Token.Begin)
+				}
+				ofs = st.Token.End
+				if st.Token.Concept == nil {
+					continue
+				}
+				matches++
+				if begin, end := st.Token.Original(); doc[begin:end] != "match" {
+					t.Fatalf("invalid match %d-%d: %q", begin, end, doc[begin:end])
+				}
+			}
+			if matches != 2*ChunkOverlap {
+				t.Fatalf("expected %d matches; got %d", 2*ChunkOverlap, matches)
+			}
+		})
+	}
+}
+
+func TestChunkedFuzzyMatch(t *testing.T) {
+	graph := NewGraph()
+	d, financial := make(Dictionary), make(Dictionary)
+	for _, entry := range []string{"river bank", "financial bank", "city council", "bank"} {
+		c, _, _ := graph.Add(entry, "p", "o")
+		d[entry] = c.ID()
+	}
+	financial["financial bank"] = d["financial bank"]
+	dfa := NewDFA(d, graph)
+	words := []string{"the", "river", "rivr", "bank", "banc", "financial", "finacial",
+		"city", "council", "counsil", "is", "near"}
+	seps := []string{" ", ", ", ".\n\n  ", "; "}
+	r := rand.New(rand.NewSource(42))
+	var b strings.Builder
+	for b.Len() < 40000 {
+		b.WriteString(words[r.Intn(len(words))])
+		b.WriteString(seps[r.Intn(len(seps))])
+	}
+	doc := b.String()
+	for _, tc := range []struct {
+		name string
+		ms   []Matcher
+	}{
+		{"k=1", []Matcher{FuzzyDFAMatcher{DFA: NewFuzzyDFA(1, dfa)}, DFAMatcher{DFA: dfa}}},
+		{"k=2,1", []Matcher{
+			FuzzyDFAMatcher{DFA: NewFuzzyDFA(2, dfa)},
+			FuzzyDFAMatcher{DFA: NewFuzzyDFA(1, dfa)},
+			DFAMatcher{DFA: dfa},
+		}},
+		{"financial,k=1", []Matcher{
+			DFAMatcher{DFA: NewDFA(financial, graph)},
+			FuzzyDFAMatcher{DFA: NewFuzzyDFA(1, dfa)},
+		}},
+	} {
+		want := chunkedMatches(t, len(doc)+1, doc, tc.ms...)
+		for _, n := range []int{7, 100, ChunkOverlap + 1, 5000, 9000} {
+			t.Run(fmt.Sprintf("%s %d", tc.name, n), func(t *testing.T) {
+				if got := chunkedMatches(t, n, doc, tc.ms...); !reflect.DeepEqual(got, want) {
+					t.Fatalf("expected %v; got %v", want, got)
+				}
+			})
+		}
+	}
+}
+
+// chunkedMatches returns the matches of the document that is read in
+// chunks of size n. Like the daemon, the document is matched by the
+// given matchers one after another.
+func chunkedMatches(t *testing.T, n int, doc string, ms ...Matcher) []string {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s := Normalize(ctx, ReadChunks(ctx, n, NewStringDocument("test", doc)))
+	for _, m := range ms {
+		s = Match(ctx, m, s)
+	}
+	var matches []string
+	for st := range s {
+		if st.Err != nil {
+			t.Fatalf("got error: %v", st.Err)
+		}
+		if st.Token.Concept == nil {
+			continue
+		}
+		urls := []string{st.Token.Concept.URL()}
+		st.Token.Concept.EachEdge(func(e Edge) {
+			urls = append(urls, fmt.Sprintf("%s:%d", e.O.URL(), e.L))
+		})
+		sort.Strings(urls[1:])
+		matches = append(matches, fmt.Sprintf("%d-%d %s", st.Token.Begin, st.Token.End, strings.Join(urls, ",")))
+	}
+	return matches
+}
+
 func TestStreamCancel(t *testing.T) {
 	ds := makeTestDocuments("A,B,C,D")
 	ctx, cancel := context.WithCancel(context.Background())
@@ -169,3 +394,78 @@ func (testm) Match(str string) MatchPos {
 	}
 	return MatchPos{Begin: i, End: i + len("match"), Concept: &Concept{url: "match"}}
 }
+
+func makeNestedDFA() DFA {
+	g := NewGraph()
+	d := make(Dictionary)
+	for _, entry := range []string{"university of new york", "new york", "new", "york city"} {
+		c, _, _ := g.Add(entry, "p", "o")
+		d[entry] = c.ID()
+	}
+	return NewDFA(d, g)
+}
+
+func TestMatchAll(t *testing.T) {
+	tests := []struct {
+		test, want string
+	}{
+		{"University of New York City", " |university of new york*|new|new york|york city| city "},
+		{"New York City", " |new york*|new|york city| city "},
+		{"York City", " |york city*| "},
+		{"the City", " the city "},
+	}
+	m := DFAMatcher{DFA: makeNestedDFA()}
+	for _, tc := range tests {
+		t.Run(tc.test, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			s := MatchAll(ctx, m, Normalizer{Fold: true}.Normalize(ctx,
+				Read(ctx, NewStringDocument("test", tc.test))))
+			var strs []string
+			for st := range s {
+				if st.Err != nil {
+					t.Fatalf("got error: %v", st.Err)
+				}
+				str := st.Token.Token
+				if st.Token.Concept != nil && !st.Token.Nested {
+					str += "*"
+				}
+				strs = append(strs, str)
+			}
+			if got := strings.Join(strs, "|"); got != tc.want {
+				t.Fatalf("expected %q; got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestChunkedMatchAll(t *testing.T) {
+	doc := strings.Repeat("New York City; ", 2*ChunkOverlap)
+	m := DFAMatcher{DFA: makeNestedDFA()}
+	for _, n := range []int{50, ChunkOverlap + 7, len(doc)} {
+		t.Run(fmt.Sprintf("%d", n), func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			s := MatchAll(ctx, m, Normalizer{Fold: true}.Normalize(ctx,
+				ReadChunks(ctx, n, NewStringDocument("test", doc))))
+			counts := make(map[string]int)
+			for st := range s {
+				if st.Err != nil {
+					t.Fatalf("got error: %v", st.Err)
+				}
+				if st.Token.Concept == nil {
+					continue
+				}
+				counts[fmt.Sprintf("%s %t", st.Token.Token, st.Token.Nested)]++
+			}
+			for _, key := range []string{"new york false", "new true", "york city true"} {
+				if counts[key] != 2*ChunkOverlap {
+					t.Fatalf("expected %d matches of %q; got %d", 2*ChunkOverlap, key, counts[key])
+				}
+			}
+			if len(counts) != 3 {
+				t.Fatalf("invalid matches: %v", counts)
+			}
+		})
+	}
+}
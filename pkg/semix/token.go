@@ -6,10 +6,40 @@ import (
 
 // Token denotes a  token in an input document. It holds the according Concept
 // or nil and its position in the input document.
+//
+// If the concept of the token was disambiguated, Resolver holds the name
+// of the resolver that chose the concept, Score the score of the chosen
+// concept and Candidates the number of possible concepts.
+//
+// Begin and End refer to the normalized input. If the token was
+// normalized, Offsets maps these positions back to the input document
+// (see Original).
+//
+// More marks chunks of a document that are followed
+// by more chunks of the same document (see ReadChunks).
+// Variant marks tokens that were matched using a generated
+// variant of a label (see WithVariants). Nested marks matches
+// that are nested in or overlap with a maximal match (see MatchAll).
 type Token struct {
 	Token, Path string
 	Concept     *Concept
 	Begin, End  int
+	Resolver    string
+	Score       float64
+	Candidates  int
+	Offsets     *OffsetMap
+	More        bool
+	Variant     bool
+	Nested      bool
+}
+
+// Original returns the begin and end positions of the token
+// in the original (not normalized) input document.
+func (t Token) Original() (int, int) {
+	if t.Offsets == nil {
+		return t.Begin, t.End
+	}
+	return t.Offsets.Begin(t.Begin), t.Offsets.End(t.End)
 }
 
 // String returns the string representation of a token.